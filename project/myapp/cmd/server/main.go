@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,11 +16,19 @@ import (
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 
+	"myapp/internal/audit"
 	"myapp/internal/config"
+	"myapp/internal/events"
+	"myapp/internal/grpcserver"
 	"myapp/internal/handlers"
 	"myapp/internal/middleware"
+	"myapp/internal/notify"
+	"myapp/internal/outbox"
+	"myapp/internal/repository/cache"
 	"myapp/internal/repository/postgres"
 	"myapp/internal/service"
+	"myapp/internal/stream"
+	"myapp/internal/webhooks"
 )
 
 func main() {
@@ -35,25 +45,119 @@ func main() {
 	}
 	defer db.Close()
 
+	// Уведомления об изменениях между репликами
+	listener := notify.NewListener(cfg.DatabaseURL, cfg.NotifyChannel)
+
 	// Настройка репозитория, сервиса и обработчика
-	repo := postgres.NewRepository(db)
-	svc := service.NewService(repo)
+	repo := postgres.NewRepository(db, postgres.NewNotifier(cfg.NotifyChannel))
+	if cfg.RowLevelSecurity {
+		repo.EnableRowLevelSecurity(cfg.DBAppRole)
+	}
+	svc := service.NewService(newCachedRepository(cfg, repo, listener), cfg.CursorSecret)
 	handler := handlers.NewHandler(svc)
+	if cfg.RequireIfMatch {
+		handler.RequireIfMatch()
+	}
+	membersIOHandler := handlers.NewMembersIOHandler(svc)
+	invitationHandler := handlers.NewInvitationHandler(svc, cfg.InvitationLinkBase)
+	joinRequestHandler := handlers.NewJoinRequestHandler(svc)
+	postHandler := handlers.NewPostHandler(svc)
+	visitHandler := handlers.NewVisitHandler(svc)
+	classHandler := handlers.NewClassHandler(svc)
+	badgeHandler := handlers.NewBadgeHandler(svc)
+	buddyHandler := handlers.NewBuddyHandler(svc)
+	gymHandler := handlers.NewGymHandler(svc)
+	organizationHandler := handlers.NewOrganizationHandler(svc)
+	privacyHandler := handlers.NewPrivacyHandler(svc)
+
+	// Журнал аудита изменяющих вызовов
+	auditService := audit.NewService(postgres.NewAuditRepository(db))
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	// Ключи идемпотентности запросов POST и PUT
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
+
+	// Хаб потоков активности групп получает изменения со всех реплик
+	hub := stream.NewHub(256, 64)
+	listener.Subscribe(func(event events.Event) { hub.Publish(context.Background(), event) })
+	listener.OnReconnect(hub.Reset)
+	streamHandler := handlers.NewStreamHandler(svc, hub)
+
+	// Настройка вебхуков
+	webhookRepo := postgres.NewWebhookRepository(db)
+	webhookHandler := handlers.NewWebhookHandler(webhooks.NewService(webhookRepo))
+	webhookWorker := webhooks.NewWorker(webhookRepo, webhooks.WorkerConfig{
+		PollInterval: time.Second,
+		BatchSize:    20,
+		Timeout:      cfg.WebhookTimeout,
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   time.Hour,
+		DisableAfter: cfg.WebhookDisableAfter,
+	})
+
+	// Настройка издателя событий и ретранслятора outbox
+	publisher, err := newPublisher(cfg)
+	if err != nil {
+		log.Fatalf("Не удалось создать издателя событий: %v", err)
+	}
+	publisher = events.NewMultiPublisher(publisher, webhooks.NewDispatcher(webhookRepo))
+	defer publisher.Close()
+
+	relay := outbox.NewRelay(postgres.NewOutboxRepository(db), publisher, outbox.Config{
+		BatchSize:       100,
+		PollInterval:    cfg.OutboxPollInterval,
+		Lease:           2 * time.Minute,
+		Retention:       cfg.OutboxRetention,
+		CleanupInterval: time.Hour,
+	})
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go relay.Run(workersCtx)
+	go webhookWorker.Run(workersCtx)
+	go cleanupIdempotencyKeys(workersCtx, idempotencyRepo, time.Hour)
+	go func() {
+		if err := listener.Run(workersCtx); err != nil {
+			log.Fatalf("Не удалось подписаться на уведомления: %v", err)
+		}
+	}()
 
 	// Настройка маршрутизатора
 	router := mux.NewRouter()
 	
 	// Применение промежуточного ПО
+	router.Use(middleware.RequestID)
 	router.Use(middleware.Logger)
 	router.Use(middleware.JSONContentType)
+	router.Use(middleware.Timezone)
 	
 	// Промежуточное ПО аутентификации для защищенных маршрутов
 	authRouter := router.PathPrefix("").Subrouter()
 	authRouter.Use(middleware.JWTAuth(cfg.JWTSecret))
+	// Повторы по ключу идемпотентности не доходят до журнала аудита
+	authRouter.Use(middleware.Idempotency(idempotencyRepo, cfg.IdempotencyTTL))
+	authRouter.Use(middleware.Audit(auditService))
 
 	// Регистрация маршрутов
 	handler.RegisterRoutes(authRouter)
+	webhookHandler.RegisterRoutes(authRouter)
+	streamHandler.RegisterRoutes(authRouter)
+	membersIOHandler.RegisterRoutes(authRouter)
+	invitationHandler.RegisterRoutes(authRouter)
+	joinRequestHandler.RegisterRoutes(authRouter)
+	postHandler.RegisterRoutes(authRouter)
+	visitHandler.RegisterRoutes(authRouter)
+	classHandler.RegisterRoutes(authRouter)
+	badgeHandler.RegisterRoutes(authRouter)
+	buddyHandler.RegisterRoutes(authRouter)
+	gymHandler.RegisterRoutes(authRouter)
+	organizationHandler.RegisterRoutes(authRouter)
+	privacyHandler.RegisterRoutes(authRouter)
+	auditHandler.RegisterRoutes(authRouter)
 	
+	// Счетчики кэша и другие метрики процесса
+	router.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+
 	// Маршрут проверки работоспособности
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -68,6 +172,8 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Открытые потоки закрываются при завершении, иначе Shutdown ждал бы их до таймаута
+	server.RegisterOnShutdown(func() { hub.Close() })
 
 	// Запуск сервера в горутине
 	go func() {
@@ -77,6 +183,21 @@ func main() {
 		}
 	}()
 
+	// Создание gRPC-сервера для внутренних вызовов
+	grpcServer, grpcHealth := grpcserver.NewGRPCServer(svc, cfg.JWTSecret, auditService)
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		log.Fatalf("Не удалось открыть порт gRPC: %v", err)
+	}
+
+	// Запуск gRPC-сервера в горутине
+	go func() {
+		log.Printf("Запуск gRPC Group Service на порту %d", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Не удалось запустить gRPC-сервер: %v", err)
+		}
+	}()
+
 	// Ожидание сигнала прерывания
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -88,9 +209,69 @@ func main() {
 
 	// Завершение работы сервера
 	log.Println("Завершение работы Group Service...")
+	grpcHealth.Shutdown()
+	grpcServer.GracefulStop()
+	stopWorkers()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Принудительное завершение работы сервера: %v", err)
 	}
 
 	log.Println("Group Service остановлен корректно")
-}
\ No newline at end of file
+}
+
+// newPublisher создает издателя событий outbox согласно конфигурации
+func newPublisher(cfg *config.Config) (events.Publisher, error) {
+	switch cfg.OutboxPublisher {
+	case "file":
+		return events.NewFilePublisher(cfg.OutboxFile)
+	case "nats":
+		return events.NewNATSPublisher(cfg.NATSURL, cfg.NATSSubjectPrefix)
+	default:
+		return events.NewStdoutPublisher(), nil
+	}
+}
+
+// cleanupIdempotencyKeys периодически удаляет истекшие ключи идемпотентности до отмены контекста
+func cleanupIdempotencyKeys(ctx context.Context, repo *postgres.IdempotencyRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := repo.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC())
+			if err != nil {
+				log.Printf("Ошибка очистки ключей идемпотентности: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Удалено истекших ключей идемпотентности: %d", deleted)
+			}
+		}
+	}
+}
+
+// newCachedRepository оборачивает репозиторий кэшем согласно конфигурации.
+// Кэш сбрасывается по уведомлениям об изменениях с других реплик.
+func newCachedRepository(cfg *config.Config, repo service.Repository, listener *notify.Listener) service.Repository {
+	var backend cache.Backend
+	switch cfg.CacheBackend {
+	case "none":
+		return repo
+	case "redis":
+		redisBackend, err := cache.NewRedis(cfg.RedisURL, "group-service:")
+		if err != nil {
+			log.Fatalf("Не удалось подключиться к Redis: %v", err)
+		}
+		backend = redisBackend
+	default:
+		backend = cache.NewLRU(cfg.CacheSize)
+	}
+
+	cached := cache.NewRepository(repo, backend, cfg.CacheTTL)
+	listener.Subscribe(cached.HandleChange)
+	listener.OnReconnect(cached.Flush)
+	expvar.Publish("group_cache", expvar.Func(func() interface{} { return cached.Stats() }))
+
+	return cached
+}
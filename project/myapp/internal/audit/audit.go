@@ -0,0 +1,135 @@
+// Package audit ведет журнал изменяющих вызовов API. Промежуточное ПО создает
+// запись на каждый вызов, а сервис дополняет ее значениями до и после изменения
+// через контекст запроса.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"myapp/internal/models"
+)
+
+// Лимиты выборки журнала
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// Repository определяет интерфейс хранилища журнала аудита
+type Repository interface {
+	// AppendAuditEntry добавляет запись. Записи журнала не изменяются и не удаляются.
+	AppendAuditEntry(ctx context.Context, entry models.AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+	// GetGymOrganizationID получает организацию зала; для неизвестного зала - пустую строку
+	GetGymOrganizationID(ctx context.Context, gymID string) (string, error)
+}
+
+// Service записывает и выбирает записи журнала аудита
+type Service struct {
+	repo Repository
+}
+
+// NewService создает новый сервис журнала аудита
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// Record добавляет запись в журнал
+func (s *Service) Record(ctx context.Context, entry models.AuditEntry) error {
+	return s.repo.AppendAuditEntry(ctx, entry)
+}
+
+// GymOrganization возвращает организацию зала, к которой относится запись вызова
+// без организации в токене, например от внутреннего сервиса
+func (s *Service) GymOrganization(ctx context.Context, gymID string) (string, error) {
+	return s.repo.GetGymOrganizationID(ctx, gymID)
+}
+
+// ListEntries возвращает записи журнала по фильтру, от новых к старым
+func (s *Service) ListEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, fmt.Errorf("%w: конец периода раньше начала", models.ErrInvalidArgument)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+
+	return s.repo.ListAuditEntries(ctx, filter)
+}
+
+// Changes накапливает изменение, которое сервис сообщил за время запроса
+type Changes struct {
+	mu     sync.Mutex
+	change *models.AuditChange
+}
+
+// changesKey - ключ контекста для изменений запроса
+type changesKey struct{}
+
+// WithChanges добавляет в контекст накопитель изменений запроса
+func WithChanges(ctx context.Context) (context.Context, *Changes) {
+	changes := &Changes{}
+	return context.WithValue(ctx, changesKey{}, changes), changes
+}
+
+// RecordChange сообщает журналу изменение, выполненное в рамках запроса.
+// Вне записываемого вызова, например в фоновой задаче, ничего не делает.
+// Если за запрос сообщено несколько изменений, в журнал попадает последнее.
+func RecordChange(ctx context.Context, change models.AuditChange) {
+	changes, ok := ctx.Value(changesKey{}).(*Changes)
+	if !ok {
+		return
+	}
+
+	changes.mu.Lock()
+	defer changes.mu.Unlock()
+	changes.change = &change
+}
+
+// Apply переносит сообщенное изменение в запись журнала. Зал и пользователь
+// из изменения используются, только если их нет в пути запроса.
+func (c *Changes) Apply(entry *models.AuditEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.change == nil {
+		return nil
+	}
+
+	if entry.GymID == nil && c.change.GymID != "" {
+		entry.GymID = &c.change.GymID
+	}
+	if entry.TargetUserID == nil && c.change.UserID != "" {
+		entry.TargetUserID = &c.change.UserID
+	}
+
+	var err error
+	if entry.Before, err = marshalValue(c.change.Before); err != nil {
+		return err
+	}
+	entry.After, err = marshalValue(c.change.After)
+	return err
+}
+
+// marshalValue сериализует значение до или после изменения; nil остается пустым
+func marshalValue(value interface{}) (models.AuditValue, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+// Outcome определяет результат вызова по коду ответа
+func Outcome(statusCode int) models.AuditOutcome {
+	if statusCode >= 400 {
+		return models.AuditFailure
+	}
+	return models.AuditSuccess
+}
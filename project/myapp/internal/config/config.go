@@ -3,15 +3,45 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config содержит конфигурацию сервиса
 type Config struct {
 	Port        int    // Порт сервиса
+	GRPCPort    int    // Порт gRPC-сервера
 	DatabaseURL string // URL базы данных
 	JWTSecret   string // Секрет для JWT
+
+	RowLevelSecurity bool   // Передавать организацию и пользователя в политики RLS
+	DBAppRole        string // Роль без владения таблицами, от имени которой выполняются запросы при RLS
+
+	OutboxPublisher    string        // Издатель событий outbox: stdout, file или nats
+	OutboxFile         string        // Файл для издателя file
+	OutboxPollInterval time.Duration // Интервал опроса outbox
+	OutboxRetention    time.Duration // Время хранения опубликованных событий
+	NATSURL            string        // URL сервера NATS
+	NATSSubjectPrefix  string        // Префикс тем NATS для событий
+	NotifyChannel      string        // Канал LISTEN/NOTIFY для изменений между репликами
+
+	CacheBackend string        // Хранилище кэша участников: memory, redis или none
+	CacheTTL     time.Duration // Время жизни записей кэша
+	CacheSize    int           // Максимальное количество записей кэша в памяти
+	RedisURL     string        // URL Redis для внешнего кэша
+
+	InvitationLinkBase string // Начало ссылки-приглашения, к которому добавляется код
+
+	IdempotencyTTL time.Duration // Время хранения ответов на запросы с Idempotency-Key
+	RequireIfMatch bool          // Требовать If-Match при изменении статуса участника
+	CursorSecret   string        // Секрет подписи курсоров постраничных списков
+
+	WebhookTimeout      time.Duration // Таймаут запроса доставки вебхука
+	WebhookMaxAttempts  int           // Количество попыток доставки вебхука
+	WebhookDisableAfter int           // Количество ошибок подряд до отключения вебхука
 }
 
 // Load загружает конфигурацию из переменных окружения
@@ -22,6 +52,12 @@ func Load() (*Config, error) {
 		return nil, errors.New("недопустимое значение PORT")
 	}
 
+	// Загрузка порта gRPC
+	grpcPort, err := strconv.Atoi(getEnv("GRPC_PORT", "9081"))
+	if err != nil {
+		return nil, errors.New("недопустимое значение GRPC_PORT")
+	}
+
 	// Загрузка URL базы данных - с поддержкой отдельных параметров подключения
 	dbURL := getEnv("DATABASE_URL", "")
 	if dbURL == "" {
@@ -48,13 +84,137 @@ func Load() (*Config, error) {
 		return nil, errors.New("требуется JWT_SECRET")
 	}
 
+	// Загрузка секрета курсоров: отдельный ключ, чтобы подпись курсоров не
+	// использовала ключ подписи токенов
+	cursorSecret := getEnv("CURSOR_SECRET", "")
+	if cursorSecret == "" {
+		return nil, errors.New("требуется CURSOR_SECRET")
+	}
+	if cursorSecret == jwtSecret {
+		return nil, errors.New("CURSOR_SECRET должен отличаться от JWT_SECRET")
+	}
+
+	// Загрузка настроек защиты на уровне строк
+	rowLevelSecurity, err := strconv.ParseBool(getEnv("DB_ROW_LEVEL_SECURITY", "false"))
+	if err != nil {
+		return nil, errors.New("недопустимое значение DB_ROW_LEVEL_SECURITY")
+	}
+
+	// Загрузка настроек outbox
+	outboxPublisher := getEnv("OUTBOX_PUBLISHER", "stdout")
+	switch outboxPublisher {
+	case "stdout", "file", "nats":
+	default:
+		return nil, errors.New("недопустимое значение OUTBOX_PUBLISHER")
+	}
+
+	outboxPollInterval, err := time.ParseDuration(getEnv("OUTBOX_POLL_INTERVAL", "1s"))
+	if err != nil {
+		return nil, errors.New("недопустимое значение OUTBOX_POLL_INTERVAL")
+	}
+
+	outboxRetention, err := time.ParseDuration(getEnv("OUTBOX_RETENTION", "24h"))
+	if err != nil {
+		return nil, errors.New("недопустимое значение OUTBOX_RETENTION")
+	}
+
+	// Загрузка настроек кэша
+	cacheBackend := getEnv("CACHE_BACKEND", "memory")
+	switch cacheBackend {
+	case "memory", "redis", "none":
+	default:
+		return nil, errors.New("недопустимое значение CACHE_BACKEND")
+	}
+
+	cacheTTL, err := time.ParseDuration(getEnv("CACHE_TTL", "30s"))
+	if err != nil {
+		return nil, errors.New("недопустимое значение CACHE_TTL")
+	}
+
+	cacheSize, err := strconv.Atoi(getEnv("CACHE_SIZE", "10000"))
+	if err != nil || cacheSize < 1 {
+		return nil, errors.New("недопустимое значение CACHE_SIZE")
+	}
+
+	// Загрузка настроек идемпотентности и условных запросов
+	idempotencyTTL, err := time.ParseDuration(getEnv("IDEMPOTENCY_TTL", "24h"))
+	if err != nil || idempotencyTTL <= 0 {
+		return nil, errors.New("недопустимое значение IDEMPOTENCY_TTL")
+	}
+
+	requireIfMatch, err := strconv.ParseBool(getEnv("REQUIRE_IF_MATCH", "false"))
+	if err != nil {
+		return nil, errors.New("недопустимое значение REQUIRE_IF_MATCH")
+	}
+
+	// Загрузка настроек вебхуков
+	webhookTimeout, err := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, errors.New("недопустимое значение WEBHOOK_TIMEOUT")
+	}
+
+	webhookMaxAttempts, err := strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "8"))
+	if err != nil || webhookMaxAttempts < 1 {
+		return nil, errors.New("недопустимое значение WEBHOOK_MAX_ATTEMPTS")
+	}
+
+	webhookDisableAfter, err := strconv.Atoi(getEnv("WEBHOOK_DISABLE_AFTER", "10"))
+	if err != nil || webhookDisableAfter < 1 {
+		return nil, errors.New("недопустимое значение WEBHOOK_DISABLE_AFTER")
+	}
+
 	return &Config{
 		Port:        port,
-		DatabaseURL: dbURL,
+		GRPCPort:    grpcPort,
+		DatabaseURL: withUTCSession(dbURL),
 		JWTSecret:   jwtSecret,
+
+		RowLevelSecurity: rowLevelSecurity,
+		DBAppRole:        getEnv("DB_APP_ROLE", ""),
+
+		OutboxPublisher:    outboxPublisher,
+		OutboxFile:         getEnv("OUTBOX_FILE", "outbox-events.log"),
+		OutboxPollInterval: outboxPollInterval,
+		OutboxRetention:    outboxRetention,
+		NATSURL:            getEnv("NATS_URL", "nats://localhost:4222"),
+		NATSSubjectPrefix:  getEnv("NATS_SUBJECT_PREFIX", "groups.events"),
+		NotifyChannel:      getEnv("NOTIFY_CHANNEL", "group_changes"),
+
+		CacheBackend: cacheBackend,
+		CacheTTL:     cacheTTL,
+		CacheSize:    cacheSize,
+		RedisURL:     getEnv("REDIS_URL", "redis://localhost:6379/0"),
+
+		InvitationLinkBase: getEnv("INVITATION_LINK_BASE", ""),
+
+		IdempotencyTTL: idempotencyTTL,
+		RequireIfMatch: requireIfMatch,
+		CursorSecret:   cursorSecret,
+
+		WebhookTimeout:      webhookTimeout,
+		WebhookMaxAttempts:  webhookMaxAttempts,
+		WebhookDisableAfter: webhookDisableAfter,
 	}, nil
 }
 
+// withUTCSession задает сеансу базы данных часовой пояс UTC, если он не указан явно,
+// чтобы метки времени читались в UTC независимо от настроек сервера
+func withUTCSession(dbURL string) string {
+	if strings.Contains(strings.ToLower(dbURL), "timezone=") {
+		return dbURL
+	}
+
+	if u, err := url.Parse(dbURL); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("timezone", "UTC")
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	// Строка подключения в формате "key=value"
+	return dbURL + " timezone=UTC"
+}
+
 // getEnv получает переменную окружения или возвращает значение по умолчанию
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
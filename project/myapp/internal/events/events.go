@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"myapp/internal/models"
+)
+
+// Type представляет тип доменного события
+type Type string
+
+// Типы доменных событий участников группы
+const (
+	MemberJoined        Type = "MemberJoined"        // пользователь вступил в группу зала
+	MemberStatusChanged Type = "MemberStatusChanged" // изменился статус участника
+	MemberLeft          Type = "MemberLeft"          // пользователь покинул группу зала
+)
+
+// Event представляет доменное событие, сохраненное в outbox
+type Event struct {
+	ID           int64           `json:"id" db:"id"`
+	Type         Type            `json:"type" db:"event_type"`
+	AggregateKey string          `json:"aggregate_key" db:"aggregate_key"`
+	Payload      json.RawMessage `json:"payload" db:"payload"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
+
+// MemberPayload содержит данные событий участника группы
+type MemberPayload struct {
+	UserID         string                `json:"user_id"`
+	GymID          string                `json:"gym_id"`
+	Status         models.ActivityStatus `json:"status,omitempty"`
+	PreviousStatus models.ActivityStatus `json:"previous_status,omitempty"`
+	Reason         string                `json:"reason,omitempty"`
+	OccurredAt     time.Time             `json:"occurred_at"`
+}
+
+// MemberKey возвращает ключ упорядочивания событий участника
+func MemberKey(userID, gymID string) string {
+	return gymID + ":" + userID
+}
+
+// DecodeMember разбирает тело события участника
+func (e Event) DecodeMember() (MemberPayload, error) {
+	var p MemberPayload
+	err := json.Unmarshal(e.Payload, &p)
+	return p, err
+}
+
+// Publisher публикует доменные события во внешнюю систему
+type Publisher interface {
+	// Publish должен вернуть ошибку, если доставка события не подтверждена
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// MultiPublisher публикует событие во все вложенные издатели
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher создает издателя, объединяющего несколько издателей.
+// Событие считается опубликованным, только если его приняли все издатели,
+// поэтому вложенные издатели должны быть идемпотентны по ID события.
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+// Publish публикует событие во все издатели
+func (m *MultiPublisher) Publish(ctx context.Context, event Event) error {
+	var errs []error
+	for _, p := range m.publishers {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close закрывает все издатели
+func (m *MultiPublisher) Close() error {
+	var errs []error
+	for _, p := range m.publishers {
+		if err := p.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
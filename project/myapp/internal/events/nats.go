@@ -0,0 +1,55 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher публикует события в NATS JetStream с подтверждением доставки
+type NATSPublisher struct {
+	conn          *nats.Conn
+	js            nats.JetStreamContext
+	subjectPrefix string
+}
+
+// NewNATSPublisher подключается к NATS и создает издателя.
+// События публикуются в темы вида <subjectPrefix>.<тип события>.
+func NewNATSPublisher(url, subjectPrefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("group-service"))
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &NATSPublisher{
+		conn:          conn,
+		js:            js,
+		subjectPrefix: subjectPrefix,
+	}, nil
+}
+
+// Publish публикует событие и ожидает подтверждения JetStream.
+// ID события передается как Nats-Msg-Id, чтобы сервер отбрасывал повторы.
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	subject := p.subjectPrefix + "." + string(event.Type)
+	_, err = p.js.Publish(subject, data, nats.Context(ctx), nats.MsgId(strconv.FormatInt(event.ID, 10)))
+	return err
+}
+
+// Close закрывает соединение с NATS
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterPublisher записывает события построчно в формате JSON (stdout или файл)
+type WriterPublisher struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewStdoutPublisher создает издателя, пишущего события в stdout
+func NewStdoutPublisher() *WriterPublisher {
+	return &WriterPublisher{w: os.Stdout}
+}
+
+// NewFilePublisher создает издателя, дописывающего события в файл
+func NewFilePublisher(path string) (*WriterPublisher, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &WriterPublisher{w: f, closer: f}, nil
+}
+
+// Publish записывает событие одной строкой JSON
+func (p *WriterPublisher) Publish(ctx context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if f, ok := p.w.(*os.File); ok && p.closer != nil {
+		return f.Sync()
+	}
+	return nil
+}
+
+// Close закрывает файл, если он был открыт издателем
+func (p *WriterPublisher) Close() error {
+	if p.closer != nil {
+		return p.closer.Close()
+	}
+	return nil
+}
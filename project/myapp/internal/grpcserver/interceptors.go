@@ -0,0 +1,147 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"myapp/internal/audit"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	groupv1 "myapp/pkg/api/group/v1"
+)
+
+// Методы, доступные без аутентификации
+var publicMethodPrefixes = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// auditedMethods - изменяющие методы, которые записываются в журнал аудита
+var auditedMethods = map[string]bool{
+	groupv1.GroupService_AddUserToGym_FullMethodName: true,
+}
+
+// auditMethod - значение поля method записей журнала о вызовах gRPC
+const auditMethod = "GRPC"
+
+// maxRequestIDLength - максимальная длина идентификатора запроса из метаданных, как в middleware.RequestID
+const maxRequestIDLength = 64
+
+// LoggerInterceptor логирует вызовы gRPC
+func LoggerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	log.Printf("Начат gRPC %s", info.FullMethod)
+
+	resp, err := handler(ctx, req)
+
+	log.Printf("Завершен gRPC %s (%s) за %v", info.FullMethod, status.Code(err), time.Since(start))
+	return resp, err
+}
+
+// JWTAuthInterceptor проверяет JWT токен из метаданных authorization
+func JWTAuthInterceptor(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for _, prefix := range publicMethodPrefixes {
+			if strings.HasPrefix(info.FullMethod, prefix) {
+				return handler(ctx, req)
+			}
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "Требуются метаданные authorization")
+		}
+
+		tokenString, ok := middleware.BearerToken(values[0])
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "Недопустимый формат авторизации")
+		}
+
+		claims, err := middleware.ParseToken(secret, tokenString)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "Недействительный токен")
+		}
+
+		return handler(middleware.WithClaims(ctx, claims), req)
+	}
+}
+
+// AuditInterceptor записывает изменяющие вызовы в журнал аудита так же, как
+// middleware.Audit записывает вызовы HTTP. Подключается после JWTAuthInterceptor.
+func AuditInterceptor(recorder middleware.AuditRecorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		claims, ok := middleware.GetClaims(ctx)
+		if !ok || !auditedMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		callCtx, changes := audit.WithChanges(ctx)
+		resp, err := handler(callCtx, req)
+
+		statusCode := httpStatus(status.Code(err))
+		middleware.RecordAuditEntry(ctx, recorder, claims, changes, models.AuditEntry{
+			RequestID:  requestID(ctx),
+			Method:     auditMethod,
+			Route:      info.FullMethod,
+			TargetIDs:  requestTargets(req),
+			StatusCode: statusCode,
+			Outcome:    audit.Outcome(statusCode),
+		})
+
+		return resp, err
+	}
+}
+
+// requestID возвращает идентификатор запроса из метаданных x-request-id или новый
+func requestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(middleware.RequestIDHeader); len(values) > 0 && values[0] != "" && len(values[0]) <= maxRequestIDLength {
+		return values[0]
+	}
+	return middleware.NewRequestID()
+}
+
+// requestTargets возвращает идентификаторы зала и пользователя из запроса под теми же
+// ключами, что и параметры пути HTTP
+func requestTargets(req interface{}) models.AuditTargets {
+	targets := models.AuditTargets{}
+	if r, ok := req.(interface{ GetGymId() string }); ok && r.GetGymId() != "" {
+		targets["gymId"] = r.GetGymId()
+	}
+	if r, ok := req.(interface{ GetUserId() string }); ok && r.GetUserId() != "" {
+		targets["userId"] = r.GetUserId()
+	}
+	return targets
+}
+
+// httpStatus возвращает код ответа HTTP, соответствующий коду gRPC, чтобы записи
+// журнала о вызовах HTTP и gRPC сравнивались по одному полю
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,151 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"myapp/internal/handlers"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	groupv1 "myapp/pkg/api/group/v1"
+)
+
+// Server реализует gRPC API сервиса групп поверх handlers.Service
+type Server struct {
+	groupv1.UnimplementedGroupServiceServer
+
+	service handlers.Service
+}
+
+// NewServer создает новую реализацию gRPC API
+func NewServer(service handlers.Service) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// NewGRPCServer создает gRPC-сервер с аутентификацией, журналом аудита, проверкой
+// работоспособности и рефлексией
+func NewGRPCServer(service handlers.Service, jwtSecret string, recorder middleware.AuditRecorder) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			LoggerInterceptor,
+			JWTAuthInterceptor(jwtSecret),
+			AuditInterceptor(recorder),
+		),
+	)
+
+	groupv1.RegisterGroupServiceServer(server, NewServer(service))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(groupv1.GroupService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server, healthServer
+}
+
+// GetGroupMembers возвращает всех участников зала
+func (s *Server) GetGroupMembers(ctx context.Context, req *groupv1.GetGroupMembersRequest) (*groupv1.GetGroupMembersResponse, error) {
+	users, err := s.service.GetGroupMembers(ctx, req.GetGymId())
+	if err != nil {
+		return nil, toStatus(err, "Ошибка получения участников группы")
+	}
+
+	members := make([]*groupv1.User, 0, len(users))
+	for _, u := range users {
+		members = append(members, toProtoUser(u))
+	}
+
+	return &groupv1.GetGroupMembersResponse{Members: members}, nil
+}
+
+// GetUserStatus возвращает статус пользователя в зале
+func (s *Server) GetUserStatus(ctx context.Context, req *groupv1.GetUserStatusRequest) (*groupv1.GetUserStatusResponse, error) {
+	activity, err := s.service.GetUserStatus(ctx, req.GetUserId(), req.GetGymId())
+	if err != nil {
+		return nil, toStatus(err, "Ошибка получения статуса пользователя")
+	}
+
+	return &groupv1.GetUserStatusResponse{Status: string(activity)}, nil
+}
+
+// AddUserToGym добавляет пользователя в группу зала
+func (s *Server) AddUserToGym(ctx context.Context, req *groupv1.AddUserToGymRequest) (*groupv1.AddUserToGymResponse, error) {
+	claims, ok := middleware.GetClaims(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Недействительный токен")
+	}
+
+	// Пользователь может добавить только себя, сервис - любого пользователя,
+	// указанного явно: у сервисного токена нет собственного участника
+	userID := req.GetUserId()
+	if userID == "" {
+		if claims.Role == middleware.RoleService {
+			return nil, status.Error(codes.InvalidArgument, "Не указан user_id")
+		}
+		userID = claims.UserID
+	}
+	if userID != claims.UserID && claims.Role != middleware.RoleService {
+		return nil, status.Error(codes.PermissionDenied, "Нельзя добавить в зал другого пользователя")
+	}
+
+	actor := models.Actor{UserID: claims.UserID, Role: claims.Role}
+	activity, err := s.service.AddUserToGym(ctx, actor, userID, req.GetGymId())
+	if err != nil {
+		return nil, toStatus(err, "Ошибка добавления пользователя в зал")
+	}
+
+	return &groupv1.AddUserToGymResponse{Status: string(activity)}, nil
+}
+
+// toProtoUser преобразует пользователя в сообщение gRPC
+func toProtoUser(u models.User) *groupv1.User {
+	return &groupv1.User{
+		Id:        u.ID,
+		Email:     u.Email,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Status:    string(u.Status),
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+}
+
+// CodeFromError возвращает код gRPC, соответствующий ошибке сервиса
+func CodeFromError(err error) codes.Code {
+	switch {
+	case errors.Is(err, models.ErrInvalidArgument):
+		return codes.InvalidArgument
+	case errors.Is(err, models.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, models.ErrForbidden):
+		return codes.PermissionDenied
+	case errors.Is(err, models.ErrConflict):
+		return codes.AlreadyExists
+	case errors.Is(err, models.ErrPreconditionFailed):
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
+
+// toStatus преобразует ошибку сервиса в статус gRPC.
+// Для внутренних ошибок используется переданное сообщение, чтобы не раскрывать детали.
+func toStatus(err error, message string) error {
+	code := CodeFromError(err)
+	if code != codes.Internal {
+		message = err.Error()
+	}
+	return status.Error(code, message)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// AuditService определяет интерфейс просмотра журнала аудита
+type AuditService interface {
+	ListEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// AuditHandler обрабатывает HTTP-запросы журнала аудита
+type AuditHandler struct {
+	service AuditService
+}
+
+// NewAuditHandler создает новый обработчик журнала аудита
+func NewAuditHandler(service AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты журнала аудита. Журнал доступен только
+// администраторам и сервисам; администратор организации видит записи своей организации.
+func (h *AuditHandler) RegisterRoutes(r *mux.Router) {
+	requireAdmin := middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService)
+
+	r.Handle("/audit-log", requireAdmin(http.HandlerFunc(h.ListEntries))).Methods("GET")
+}
+
+// ListEntries обрабатывает выборку журнала аудита с фильтрами actor_id, gym_id,
+// from и to (RFC3339), а также постраничным выводом через before_id и limit
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		ActorID: query.Get("actor_id"),
+		GymID:   query.Get("gym_id"),
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое значение "+param.name)
+			return
+		}
+		*param.dest = &parsed
+	}
+
+	if value := query.Get("before_id"); value != "" {
+		beforeID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || beforeID <= 0 {
+			httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое значение before_id")
+			return
+		}
+		filter.BeforeID = beforeID
+	}
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+
+	entries, err := h.service.ListEntries(r.Context(), filter)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения журнала аудита")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, entries)
+}
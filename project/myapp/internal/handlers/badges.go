@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// BadgeService определяет интерфейс серий посещений и наград
+type BadgeService interface {
+	GetMemberStats(ctx context.Context, actor models.Actor, gymID, userID string) (models.MemberStats, error)
+	RecomputeBadges(ctx context.Context, gymID string) (models.RecomputeResult, error)
+	ListBadgeRules(ctx context.Context, gymID string) ([]models.BadgeRule, error)
+	CreateBadgeRule(ctx context.Context, gymID string, rule models.BadgeRule) (models.BadgeRule, error)
+	DeleteBadgeRule(ctx context.Context, gymID, ruleID string) error
+}
+
+// BadgeHandler обрабатывает HTTP-запросы серий и наград
+type BadgeHandler struct {
+	service BadgeService
+}
+
+// NewBadgeHandler создает новый обработчик наград
+func NewBadgeHandler(service BadgeService) *BadgeHandler {
+	return &BadgeHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты наград. Правила наград и пересчет
+// доступны только администраторам и сервисам.
+func (h *BadgeHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/groups/{gymId}/members/{userId}/stats", h.GetMemberStats).Methods("GET")
+
+	admin := r.PathPrefix("/gyms/{gymId}").Subrouter()
+	admin.Use(middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService))
+	admin.HandleFunc("/badge-rules", h.ListBadgeRules).Methods("GET")
+	admin.HandleFunc("/badge-rules", h.CreateBadgeRule).Methods("POST")
+	admin.HandleFunc("/badge-rules/{ruleId}", h.DeleteBadgeRule).Methods("DELETE")
+	admin.HandleFunc("/badges/recompute", h.RecomputeBadges).Methods("POST")
+}
+
+// GetMemberStats обрабатывает получение серий и наград участника
+func (h *BadgeHandler) GetMemberStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetMemberStats(r.Context(), actor, vars["gymId"], vars["userId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения статистики участника")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, stats)
+}
+
+// RecomputeBadges обрабатывает пересчет серий и наград зала
+func (h *BadgeHandler) RecomputeBadges(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.RecomputeBadges(r.Context(), mux.Vars(r)["gymId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка пересчета наград")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, result)
+}
+
+// ListBadgeRules обрабатывает получение правил наград зала
+func (h *BadgeHandler) ListBadgeRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListBadgeRules(r.Context(), mux.Vars(r)["gymId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения правил наград")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string][]models.BadgeRule{"rules": rules})
+}
+
+// CreateBadgeRule обрабатывает создание правила награды
+func (h *BadgeHandler) CreateBadgeRule(w http.ResponseWriter, r *http.Request) {
+	var rule models.BadgeRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	rule, err := h.service.CreateBadgeRule(r.Context(), mux.Vars(r)["gymId"], rule)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка создания правила награды")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, rule)
+}
+
+// DeleteBadgeRule обрабатывает удаление правила награды
+func (h *BadgeHandler) DeleteBadgeRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := h.service.DeleteBadgeRule(r.Context(), vars["gymId"], vars["ruleId"]); err != nil {
+		respondWithServiceError(w, err, "Ошибка удаления правила награды")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Правило награды успешно удалено"})
+}
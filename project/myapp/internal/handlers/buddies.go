@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// BuddyService определяет интерфейс подбора партнеров по тренировкам
+type BuddyService interface {
+	GetBuddySuggestions(ctx context.Context, actor models.Actor, gymID string, weeks int) (models.BuddiesResponse, error)
+	GetBuddyPreferences(ctx context.Context, actor models.Actor, gymID string) (models.BuddyPreferences, error)
+	UpdateBuddyPreferences(ctx context.Context, actor models.Actor, gymID string, prefs models.BuddyPreferences) (models.BuddyPreferences, error)
+}
+
+// BuddyHandler обрабатывает HTTP-запросы подбора партнеров
+type BuddyHandler struct {
+	service BuddyService
+}
+
+// NewBuddyHandler создает новый обработчик подбора партнеров
+func NewBuddyHandler(service BuddyService) *BuddyHandler {
+	return &BuddyHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты подбора партнеров
+func (h *BuddyHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/groups/{gymId}/buddies", h.GetBuddySuggestions).Methods("GET")
+	r.HandleFunc("/groups/{gymId}/buddies/preferences", h.GetBuddyPreferences).Methods("GET")
+	r.HandleFunc("/groups/{gymId}/buddies/preferences", h.UpdateBuddyPreferences).Methods("PUT")
+}
+
+// GetBuddySuggestions обрабатывает получение подсказок партнеров.
+// Параметр weeks задает, за сколько последних недель сравниваются посещения.
+func (h *BuddyHandler) GetBuddySuggestions(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	weeks, _ := strconv.Atoi(r.URL.Query().Get("weeks"))
+
+	response, err := h.service.GetBuddySuggestions(r.Context(), actor, mux.Vars(r)["gymId"], weeks)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка подбора партнеров")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// GetBuddyPreferences обрабатывает получение настроек подбора партнеров
+func (h *BuddyHandler) GetBuddyPreferences(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.service.GetBuddyPreferences(r.Context(), actor, mux.Vars(r)["gymId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения настроек подбора партнеров")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, prefs)
+}
+
+// UpdateBuddyPreferences обрабатывает изменение настроек подбора партнеров
+func (h *BuddyHandler) UpdateBuddyPreferences(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var prefs models.BuddyPreferences
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	prefs, err := h.service.UpdateBuddyPreferences(r.Context(), actor, mux.Vars(r)["gymId"], prefs)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка изменения настроек подбора партнеров")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, prefs)
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	"myapp/pkg/auth"
+	httputil "myapp/pkg/http"
+)
+
+// defaultScheduleRange - период расписания, если границы не заданы в запросе
+const defaultScheduleRange = 7 * 24 * time.Hour
+
+// ClassService определяет интерфейс расписания групповых занятий
+type ClassService interface {
+	CreateClass(ctx context.Context, gymID, createdBy string, req models.ClassRequest) (models.Class, error)
+	ListClasses(ctx context.Context, gymID string) ([]models.Class, error)
+	UpdateClass(ctx context.Context, gymID, classID string, req models.ClassRequest) (models.Class, error)
+	DeleteClass(ctx context.Context, gymID, classID string) error
+	GetSchedule(ctx context.Context, actor models.Actor, gymID string, from, to time.Time) (models.Schedule, error)
+	BookClass(ctx context.Context, actor models.Actor, gymID, classID string, startsAt time.Time) (models.Booking, error)
+	CancelBooking(ctx context.Context, actor models.Actor, gymID, classID string, startsAt time.Time) (models.Booking, error)
+}
+
+// ClassHandler обрабатывает HTTP-запросы расписания и записи на занятия
+type ClassHandler struct {
+	service ClassService
+}
+
+// NewClassHandler создает новый обработчик расписания
+func NewClassHandler(service ClassService) *ClassHandler {
+	return &ClassHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты расписания. Занятия ведут администраторы,
+// расписание и запись доступны участникам группы.
+func (h *ClassHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/groups/{gymId}/schedule", h.GetSchedule).Methods("GET")
+	r.HandleFunc("/groups/{gymId}/classes/{classId}/bookings", h.BookClass).Methods("POST")
+	r.HandleFunc("/groups/{gymId}/classes/{classId}/bookings", h.CancelBooking).Methods("DELETE")
+
+	admin := r.PathPrefix("/gyms/{gymId}/classes").Subrouter()
+	admin.Use(middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService))
+	admin.HandleFunc("", h.CreateClass).Methods("POST")
+	admin.HandleFunc("", h.ListClasses).Methods("GET")
+	admin.HandleFunc("/{classId}", h.UpdateClass).Methods("PUT")
+	admin.HandleFunc("/{classId}", h.DeleteClass).Methods("DELETE")
+}
+
+// CreateClass обрабатывает создание занятия
+func (h *ClassHandler) CreateClass(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserIDFromToken(r)
+	if err != nil {
+		httputil.RespondWithError(w, http.StatusUnauthorized, "Недействительный токен")
+		return
+	}
+
+	var req models.ClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	class, err := h.service.CreateClass(r.Context(), mux.Vars(r)["gymId"], userID, req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка создания занятия")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, class)
+}
+
+// ListClasses обрабатывает получение занятий зала
+func (h *ClassHandler) ListClasses(w http.ResponseWriter, r *http.Request) {
+	classes, err := h.service.ListClasses(r.Context(), mux.Vars(r)["gymId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения занятий")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string][]models.Class{"classes": classes})
+}
+
+// UpdateClass обрабатывает изменение занятия
+func (h *ClassHandler) UpdateClass(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req models.ClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	class, err := h.service.UpdateClass(r.Context(), vars["gymId"], vars["classId"], req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка изменения занятия")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, class)
+}
+
+// DeleteClass обрабатывает удаление занятия
+func (h *ClassHandler) DeleteClass(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := h.service.DeleteClass(r.Context(), vars["gymId"], vars["classId"]); err != nil {
+		respondWithServiceError(w, err, "Ошибка удаления занятия")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Занятие успешно удалено"})
+}
+
+// GetSchedule обрабатывает получение расписания зала.
+// Границы периода from и to задаются в формате RFC 3339.
+func (h *ClassHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	from := time.Now()
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое значение from")
+			return
+		}
+		from = parsed
+	}
+	to := from.Add(defaultScheduleRange)
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое значение to")
+			return
+		}
+		to = parsed
+	}
+
+	schedule, err := h.service.GetSchedule(r.Context(), actor, mux.Vars(r)["gymId"], from, to)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения расписания")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, schedule)
+}
+
+// BookClass обрабатывает запись на занятие
+func (h *ClassHandler) BookClass(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	booking, err := h.service.BookClass(r.Context(), actor, vars["gymId"], vars["classId"], req.StartsAt)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка записи на занятие")
+		return
+	}
+
+	status := http.StatusCreated
+	if booking.Status == models.BookingWaitlisted {
+		status = http.StatusAccepted
+	}
+
+	respondWithJSON(w, r, status, booking)
+}
+
+// CancelBooking обрабатывает отмену записи на занятие.
+// Время начала занятия передается в параметре starts_at в формате RFC 3339.
+func (h *ClassHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	startsAt, err := time.Parse(time.RFC3339, r.URL.Query().Get("starts_at"))
+	if err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое значение starts_at")
+		return
+	}
+
+	booking, err := h.service.CancelBooking(r.Context(), actor, vars["gymId"], vars["classId"], startsAt)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка отмены записи на занятие")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, booking)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// StatusFromError возвращает HTTP-статус, соответствующий ошибке сервиса
+func StatusFromError(err error) int {
+	switch {
+	case errors.Is(err, models.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondWithServiceError отправляет ответ с ошибкой сервиса.
+// Для внутренних ошибок используется переданное сообщение, чтобы не раскрывать детали.
+func respondWithServiceError(w http.ResponseWriter, err error, message string) {
+	code := StatusFromError(err)
+	if code != http.StatusInternalServerError {
+		message = err.Error()
+	}
+	httputil.RespondWithError(w, code, message)
+}
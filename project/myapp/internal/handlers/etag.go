@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+)
+
+// Заголовки Cache-Control списков участников. Список зала можно недолго брать из
+// кэша клиента, своя группа всегда перепроверяется условным запросом.
+const (
+	membersCacheControl = "private, max-age=5, must-revalidate"
+	myGroupCacheControl = "private, no-cache"
+)
+
+// formatETag возвращает сильный ETag для версии записи
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch разбирает заголовок If-Match в список ожидаемых версий.
+// Пустой заголовок и "*" не ограничивают версию и дают пустой список.
+// Слабые и нераспознанные ETag не совпадают ни с одной версией: если других
+// нет, возвращается false.
+func parseIfMatch(header string) ([]int64, bool) {
+	if strings.TrimSpace(header) == "" {
+		return nil, true
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, true
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, len(versions) > 0
+}
+
+// writeNotModified задает ETag, Last-Modified и Cache-Control ответа по версии списка
+// и отвечает 304, если у клиента актуальная версия. If-None-Match проверяется
+// раньше If-Modified-Since. Возвращает true, если ответ уже отправлен.
+// Ответы в разных часовых поясах отличаются байтами, поэтому пояс входит в ETag.
+func writeNotModified(w http.ResponseWriter, r *http.Request, version models.ListVersion, cacheControl string) bool {
+	etag := `"` + version.Hash + `"`
+	if loc, ok := middleware.GetLocation(r.Context()); ok {
+		etag = `"` + version.Hash + "@" + loc.String() + `"`
+	}
+	lastModified := version.LastModified.UTC().Truncate(time.Second)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", cacheControl)
+
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		if !etagMatches(header, etag) {
+			return false
+		}
+	} else if header := r.Header.Get("If-Modified-Since"); header != "" {
+		since, err := http.ParseTime(header)
+		if err != nil || lastModified.After(since) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	// У ответа 304 нет тела
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches сравнивает ETag из If-None-Match со слабым сравнением
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// GymService определяет интерфейс управления залами
+type GymService interface {
+	CreateGym(ctx context.Context, req models.GymRequest) (models.Gym, error)
+	GetGym(ctx context.Context, gymID string) (models.Gym, error)
+	ListGyms(ctx context.Context) ([]models.Gym, error)
+	UpdateGym(ctx context.Context, gymID string, req models.GymRequest) (models.Gym, error)
+	DeleteGym(ctx context.Context, gymID string) error
+}
+
+// GymHandler обрабатывает HTTP-запросы залов
+type GymHandler struct {
+	service GymService
+}
+
+// NewGymHandler создает новый обработчик залов
+func NewGymHandler(service GymService) *GymHandler {
+	return &GymHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты залов. Просматривать залы может любой
+// пользователь, создавать, изменять и удалять - только администраторы и сервисы.
+func (h *GymHandler) RegisterRoutes(r *mux.Router) {
+	requireAdmin := middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService)
+
+	r.HandleFunc("/gyms", h.ListGyms).Methods("GET")
+	r.HandleFunc("/gyms/{gymId}", h.GetGym).Methods("GET")
+	r.Handle("/gyms", requireAdmin(http.HandlerFunc(h.CreateGym))).Methods("POST")
+	r.Handle("/gyms/{gymId}", requireAdmin(http.HandlerFunc(h.UpdateGym))).Methods("PUT")
+	r.Handle("/gyms/{gymId}", requireAdmin(http.HandlerFunc(h.DeleteGym))).Methods("DELETE")
+}
+
+// ListGyms обрабатывает получение списка залов
+func (h *GymHandler) ListGyms(w http.ResponseWriter, r *http.Request) {
+	gyms, err := h.service.ListGyms(r.Context())
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения залов")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, gyms)
+}
+
+// GetGym обрабатывает получение зала
+func (h *GymHandler) GetGym(w http.ResponseWriter, r *http.Request) {
+	gym, err := h.service.GetGym(r.Context(), mux.Vars(r)["gymId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения зала")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, gym)
+}
+
+// CreateGym обрабатывает создание зала
+func (h *GymHandler) CreateGym(w http.ResponseWriter, r *http.Request) {
+	var req models.GymRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	gym, err := h.service.CreateGym(r.Context(), req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка создания зала")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, gym)
+}
+
+// UpdateGym обрабатывает изменение зала
+func (h *GymHandler) UpdateGym(w http.ResponseWriter, r *http.Request) {
+	var req models.GymRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	gym, err := h.service.UpdateGym(r.Context(), mux.Vars(r)["gymId"], req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка изменения зала")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, gym)
+}
+
+// DeleteGym обрабатывает удаление зала
+func (h *GymHandler) DeleteGym(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteGym(r.Context(), mux.Vars(r)["gymId"]); err != nil {
+		respondWithServiceError(w, err, "Ошибка удаления зала")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Зал успешно удален"})
+}
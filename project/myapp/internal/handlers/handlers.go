@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
 	"myapp/internal/models"
 	"myapp/pkg/auth"
 	httputil "myapp/pkg/http"
@@ -14,15 +15,23 @@ import (
 // Service определяет интерфейс для бизнес-логики
 type Service interface {
 	GetGroupMembers(ctx context.Context, gymID string) ([]models.User, error)
+	ListGroupMembersPage(ctx context.Context, gymID, cursor string, limit int) (models.MembersPage, error)
 	GetUserGroup(ctx context.Context, userID string) (models.Group, []models.User, error)
-	UpdateUserStatus(ctx context.Context, userID, gymID string, status models.ActivityStatus) error
+	GetGroupMembersVersion(ctx context.Context, gymID string) (models.ListVersion, error)
+	GetUserGroupVersion(ctx context.Context, userID string) (models.ListVersion, error)
+	UpdateUserStatus(ctx context.Context, actor models.Actor, userID, gymID string, status models.ActivityStatus, reason string, expectedVersions []int64) (models.MemberStatus, error)
+	AllowedTransitions(ctx context.Context, actor models.Actor, userID, gymID string) (models.AllowedTransitionsResponse, error)
+	ListStatusHistory(ctx context.Context, actor models.Actor, gymID, userID, cursor string, limit int) (models.StatusHistoryPage, error)
 	GetUserStatus(ctx context.Context, userID, gymID string) (models.ActivityStatus, error)
-	AddUserToGym(ctx context.Context, userID, gymID string) error
+	GetMemberStatus(ctx context.Context, userID, gymID string) (models.MemberStatus, error)
+	AddUserToGym(ctx context.Context, actor models.Actor, userID, gymID string) (models.ActivityStatus, error)
+	RemoveUserFromGym(ctx context.Context, actor models.Actor, gymID string) error
 }
 
 // Handler обрабатывает HTTP-запросы
 type Handler struct {
-	service Service
+	service        Service
+	requireIfMatch bool
 }
 
 // NewHandler создает новый обработчик
@@ -32,16 +41,27 @@ func NewHandler(service Service) *Handler {
 	}
 }
 
+// RequireIfMatch требует заголовок If-Match при изменении статуса участника:
+// запрос без него отклоняется кодом 428
+func (h *Handler) RequireIfMatch() {
+	h.requireIfMatch = true
+}
+
 // RegisterRoutes регистрирует маршруты обработчика
 func (h *Handler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/groups/{gymId}/members", h.GetGroupMembers).Methods("GET")
 	r.HandleFunc("/groups/my", h.GetMyGroup).Methods("GET")
 	r.HandleFunc("/groups/{gymId}/members/{userId}/status", h.GetUserStatus).Methods("GET")
 	r.HandleFunc("/groups/{gymId}/members/{userId}/status", h.UpdateUserStatus).Methods("PUT")
+	r.HandleFunc("/groups/{gymId}/members/{userId}/status/transitions", h.GetStatusTransitions).Methods("GET")
+	r.HandleFunc("/groups/{gymId}/members/{userId}/status/history", h.GetStatusHistory).Methods("GET")
 	r.HandleFunc("/groups/{gymId}/members", h.AddUserToGym).Methods("POST")
+	r.HandleFunc("/groups/{gymId}/members", h.LeaveGym).Methods("DELETE")
 }
 
-// GetGroupMembers обрабатывает получение участников зала
+// GetGroupMembers обрабатывает получение участников зала.
+// Поддерживает условные запросы If-None-Match и If-Modified-Since.
+// С параметрами cursor или limit возвращает страницу списка в порядке вступления.
 func (h *Handler) GetGroupMembers(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gymID := vars["gymId"]
@@ -51,9 +71,31 @@ func (h *Handler) GetGroupMembers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isPageRequest(r) {
+		cursor, limit := pageParams(r)
+		page, err := h.service.ListGroupMembersPage(r.Context(), gymID, cursor, limit)
+		if err != nil {
+			respondWithServiceError(w, err, "Ошибка получения участников группы")
+			return
+		}
+		page.Links = pageLinks(r, page.NextCursor, page.PrevCursor)
+
+		respondWithJSON(w, r, http.StatusOK, page)
+		return
+	}
+
+	version, err := h.service.GetGroupMembersVersion(r.Context(), gymID)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения участников группы")
+		return
+	}
+	if writeNotModified(w, r, version, membersCacheControl) {
+		return
+	}
+
 	users, err := h.service.GetGroupMembers(r.Context(), gymID)
 	if err != nil {
-		httputil.RespondWithError(w, http.StatusInternalServerError, "Ошибка получения участников группы")
+		respondWithServiceError(w, err, "Ошибка получения участников группы")
 		return
 	}
 
@@ -61,10 +103,11 @@ func (h *Handler) GetGroupMembers(w http.ResponseWriter, r *http.Request) {
 		Members: users,
 	}
 
-	httputil.RespondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
-// GetMyGroup обрабатывает получение своей группы пользователем
+// GetMyGroup обрабатывает получение своей группы пользователем.
+// Поддерживает условные запросы If-None-Match и If-Modified-Since.
 func (h *Handler) GetMyGroup(w http.ResponseWriter, r *http.Request) {
 	// Получаем ID пользователя из JWT токена
 	userID, err := auth.GetUserIDFromToken(r)
@@ -73,9 +116,18 @@ func (h *Handler) GetMyGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := h.service.GetUserGroupVersion(r.Context(), userID)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения группы")
+		return
+	}
+	if writeNotModified(w, r, version, myGroupCacheControl) {
+		return
+	}
+
 	group, members, err := h.service.GetUserGroup(r.Context(), userID)
 	if err != nil {
-		httputil.RespondWithError(w, http.StatusInternalServerError, "Ошибка получения группы")
+		respondWithServiceError(w, err, "Ошибка получения группы")
 		return
 	}
 
@@ -87,7 +139,7 @@ func (h *Handler) GetMyGroup(w http.ResponseWriter, r *http.Request) {
 		Members: members,
 	}
 
-	httputil.RespondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // GetUserStatus обрабатывает получение статуса пользователя в зале
@@ -101,22 +153,19 @@ func (h *Handler) GetUserStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	status, err := h.service.GetUserStatus(r.Context(), userID, gymID)
+	status, err := h.service.GetMemberStatus(r.Context(), userID, gymID)
 	if err != nil {
-		httputil.RespondWithError(w, http.StatusInternalServerError, "Ошибка получения статуса пользователя")
+		respondWithServiceError(w, err, "Ошибка получения статуса пользователя")
 		return
 	}
 
-	response := struct {
-		Status models.ActivityStatus `json:"status"`
-	}{
-		Status: status,
-	}
-
-	httputil.RespondWithJSON(w, http.StatusOK, response)
+	w.Header().Set("ETag", formatETag(status.Version))
+	respondWithJSON(w, r, http.StatusOK, status)
 }
 
-// UpdateUserStatus обрабатывает обновление статуса пользователя в зале
+// UpdateUserStatus обрабатывает обновление статуса пользователя в зале.
+// Заголовок If-Match с ETag из GET .../status защищает от перезаписи чужого изменения:
+// если статус успел измениться, запрос отклоняется кодом 412.
 func (h *Handler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gymID := vars["gymId"]
@@ -127,18 +176,91 @@ func (h *Handler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
 	var req models.UpdateStatusRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
 		return
 	}
 
-	if err := h.service.UpdateUserStatus(r.Context(), userID, gymID, req.Status); err != nil {
-		httputil.RespondWithError(w, http.StatusInternalServerError, "Ошибка обновления статуса пользователя")
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" && h.requireIfMatch {
+		httputil.RespondWithError(w, http.StatusPreconditionRequired, "Требуется заголовок If-Match")
+		return
+	}
+	versions, ok := parseIfMatch(ifMatch)
+	if !ok {
+		httputil.RespondWithError(w, http.StatusPreconditionFailed, models.ErrPreconditionFailed.Error())
+		return
+	}
+
+	status, err := h.service.UpdateUserStatus(r.Context(), actor, userID, gymID, req.Status, req.Reason, versions)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка обновления статуса пользователя")
 		return
 	}
 
-	httputil.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Статус успешно обновлен"})
+	// Новый ETag позволяет изменить статус повторно без лишнего чтения. Версия получена
+	// в транзакции изменения, поэтому не может оказаться версией чужого изменения.
+	w.Header().Set("ETag", formatETag(status.Version))
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Статус успешно обновлен"})
+}
+
+// GetStatusTransitions обрабатывает получение статусов, в которые вызывающий может перевести участника
+func (h *Handler) GetStatusTransitions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gymID := vars["gymId"]
+	userID := vars["userId"]
+
+	if gymID == "" || userID == "" {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Требуются ID зала и ID пользователя")
+		return
+	}
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.AllowedTransitions(r.Context(), actor, userID, gymID)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения допустимых переходов статуса")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// GetStatusHistory обрабатывает получение истории статусов участника постранично
+func (h *Handler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gymID := vars["gymId"]
+	userID := vars["userId"]
+
+	if gymID == "" || userID == "" {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Требуются ID зала и ID пользователя")
+		return
+	}
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := h.service.ListStatusHistory(r.Context(), actor, gymID, userID, cursor, limit)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения истории статусов")
+		return
+	}
+	page.Links = pageLinks(r, page.NextCursor, page.PrevCursor)
+
+	respondWithJSON(w, r, http.StatusOK, page)
 }
 
 // AddUserToGym обрабатывает добавление пользователя в зал
@@ -151,17 +273,59 @@ func (h *Handler) AddUserToGym(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Получаем ID пользователя из JWT токена
-	userID, err := auth.GetUserIDFromToken(r)
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.AddUserToGym(r.Context(), actor, actor.UserID, gymID)
 	if err != nil {
-		httputil.RespondWithError(w, http.StatusUnauthorized, "Недействительный токен")
+		respondWithServiceError(w, err, "Ошибка добавления пользователя в зал")
+		return
+	}
+
+	if status == models.PendingStatus {
+		respondWithJSON(w, r, http.StatusAccepted, map[string]string{
+			"message": "Заявка на вступление отправлена администратору",
+			"status":  string(status),
+		})
 		return
 	}
 
-	if err := h.service.AddUserToGym(r.Context(), userID, gymID); err != nil {
-		httputil.RespondWithError(w, http.StatusInternalServerError, "Ошибка добавления пользователя в зал")
+	respondWithJSON(w, r, http.StatusCreated, map[string]string{
+		"message": "Пользователь успешно добавлен в зал",
+		"status":  string(status),
+	})
+}
+
+// LeaveGym обрабатывает выход пользователя из зала
+func (h *Handler) LeaveGym(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gymID := vars["gymId"]
+
+	if gymID == "" {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Требуется ID зала")
 		return
 	}
 
-	httputil.RespondWithJSON(w, http.StatusCreated, map[string]string{"message": "Пользователь успешно добавлен в зал"})
-}
\ No newline at end of file
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveUserFromGym(r.Context(), actor, gymID); err != nil {
+		respondWithServiceError(w, err, "Ошибка выхода пользователя из зала")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Пользователь успешно покинул зал"})
+}
+
+// requestActor возвращает вызывающую сторону из JWT токена или отвечает 401
+func requestActor(w http.ResponseWriter, r *http.Request) (models.Actor, bool) {
+	actor, ok := middleware.GetActor(r.Context())
+	if !ok {
+		httputil.RespondWithError(w, http.StatusUnauthorized, "Недействительный токен")
+	}
+	return actor, ok
+}
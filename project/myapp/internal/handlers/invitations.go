@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// InvitationService определяет интерфейс приглашений и настроек вступления в зал
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, actor models.Actor, gymID string, req models.CreateInvitationRequest) (models.Invitation, error)
+	ListInvitations(ctx context.Context, gymID string) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, gymID, invitationID string) (models.Invitation, error)
+	RedeemInvitation(ctx context.Context, actor models.Actor, code string) (models.Invitation, error)
+	GetGymSettings(ctx context.Context, gymID string) (models.GymSettings, error)
+	UpdateGymSettings(ctx context.Context, settings models.GymSettings) (models.GymSettings, error)
+}
+
+// InvitationHandler обрабатывает HTTP-запросы приглашений
+type InvitationHandler struct {
+	service  InvitationService
+	linkBase string
+}
+
+// NewInvitationHandler создает новый обработчик приглашений.
+// linkBase - начало ссылки-приглашения, к которому добавляется код; пустое значение отключает ссылки.
+func NewInvitationHandler(service InvitationService, linkBase string) *InvitationHandler {
+	return &InvitationHandler{
+		service:  service,
+		linkBase: linkBase,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты приглашений
+func (h *InvitationHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/invitations/{code}/redeem", h.RedeemInvitation).Methods("POST")
+
+	admin := r.PathPrefix("/gyms/{gymId}").Subrouter()
+	admin.Use(middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService))
+	admin.HandleFunc("/invitations", h.CreateInvitation).Methods("POST")
+	admin.HandleFunc("/invitations", h.ListInvitations).Methods("GET")
+	admin.HandleFunc("/invitations/{invitationId}", h.RevokeInvitation).Methods("DELETE")
+	admin.HandleFunc("/settings", h.GetGymSettings).Methods("GET")
+	admin.HandleFunc("/settings", h.UpdateGymSettings).Methods("PUT")
+}
+
+// withLink добавляет к приглашению ссылку
+func (h *InvitationHandler) withLink(invitation models.Invitation) models.Invitation {
+	if h.linkBase != "" {
+		invitation.Link = h.linkBase + invitation.Code
+	}
+	return invitation
+}
+
+// CreateInvitation обрабатывает создание приглашения
+func (h *InvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	gymID := mux.Vars(r)["gymId"]
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	invitation, err := h.service.CreateInvitation(r.Context(), actor, gymID, req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка создания приглашения")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, h.withLink(invitation))
+}
+
+// ListInvitations обрабатывает получение приглашений зала
+func (h *InvitationHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	gymID := mux.Vars(r)["gymId"]
+
+	invitations, err := h.service.ListInvitations(r.Context(), gymID)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения приглашений")
+		return
+	}
+
+	for i := range invitations {
+		invitations[i] = h.withLink(invitations[i])
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string][]models.Invitation{"invitations": invitations})
+}
+
+// RevokeInvitation обрабатывает отзыв приглашения
+func (h *InvitationHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	invitation, err := h.service.RevokeInvitation(r.Context(), vars["gymId"], vars["invitationId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка отзыва приглашения")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, h.withLink(invitation))
+}
+
+// RedeemInvitation обрабатывает вступление в зал по приглашению
+func (h *InvitationHandler) RedeemInvitation(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.service.RedeemInvitation(r.Context(), actor, code)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка вступления в зал по приглашению")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, map[string]string{
+		"message": "Пользователь успешно добавлен в зал",
+		"gym_id":  invitation.GymID,
+	})
+}
+
+// GetGymSettings обрабатывает получение настроек зала
+func (h *InvitationHandler) GetGymSettings(w http.ResponseWriter, r *http.Request) {
+	gymID := mux.Vars(r)["gymId"]
+
+	settings, err := h.service.GetGymSettings(r.Context(), gymID)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения настроек зала")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, settings)
+}
+
+// UpdateGymSettings обрабатывает изменение настроек зала
+func (h *InvitationHandler) UpdateGymSettings(w http.ResponseWriter, r *http.Request) {
+	gymID := mux.Vars(r)["gymId"]
+
+	var settings models.GymSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+	settings.GymID = gymID
+
+	settings, err := h.service.UpdateGymSettings(r.Context(), settings)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка изменения настроек зала")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, settings)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// JoinRequestService определяет интерфейс рассмотрения заявок на вступление
+type JoinRequestService interface {
+	ListJoinRequests(ctx context.Context, gymID string) ([]models.JoinRequest, error)
+	ApproveJoinRequest(ctx context.Context, reviewer models.Actor, userID, gymID, reason string) error
+	RejectJoinRequest(ctx context.Context, reviewer models.Actor, userID, gymID, reason string) error
+}
+
+// JoinRequestHandler обрабатывает HTTP-запросы рассмотрения заявок
+type JoinRequestHandler struct {
+	service JoinRequestService
+}
+
+// NewJoinRequestHandler создает новый обработчик заявок
+func NewJoinRequestHandler(service JoinRequestService) *JoinRequestHandler {
+	return &JoinRequestHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты заявок. Доступ есть только у администраторов и сервисов.
+func (h *JoinRequestHandler) RegisterRoutes(r *mux.Router) {
+	s := r.PathPrefix("/gyms/{gymId}/join-requests").Subrouter()
+	s.Use(middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService))
+
+	s.HandleFunc("", h.ListJoinRequests).Methods("GET")
+	s.HandleFunc("/{userId}/approve", h.ApproveJoinRequest).Methods("POST")
+	s.HandleFunc("/{userId}/reject", h.RejectJoinRequest).Methods("POST")
+}
+
+// ListJoinRequests обрабатывает получение заявок зала
+func (h *JoinRequestHandler) ListJoinRequests(w http.ResponseWriter, r *http.Request) {
+	gymID := mux.Vars(r)["gymId"]
+
+	requests, err := h.service.ListJoinRequests(r.Context(), gymID)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения заявок")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string][]models.JoinRequest{"requests": requests})
+}
+
+// ApproveJoinRequest обрабатывает одобрение заявки
+func (h *JoinRequestHandler) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.ApproveJoinRequest, "Заявка одобрена")
+}
+
+// RejectJoinRequest обрабатывает отклонение заявки
+func (h *JoinRequestHandler) RejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.RejectJoinRequest, "Заявка отклонена")
+}
+
+// review применяет решение администратора к заявке
+func (h *JoinRequestHandler) review(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, reviewer models.Actor, userID, gymID, reason string) error, message string) {
+	vars := mux.Vars(r)
+
+	reviewer, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+			return
+		}
+	}
+
+	if err := decide(r.Context(), reviewer, vars["userId"], vars["gymId"], req.Reason); err != nil {
+		respondWithServiceError(w, err, "Ошибка рассмотрения заявки")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": message})
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// maxImportBodySize - максимальный размер тела запроса импорта
+const maxImportBodySize = 10 << 20
+
+// MembersIOService определяет интерфейс импорта и экспорта участников
+type MembersIOService interface {
+	ImportMembers(ctx context.Context, actor models.Actor, gymID string, rows []models.ImportMemberRow, dryRun bool) (models.ImportReport, error)
+	ExportMembers(ctx context.Context, gymID string, fn func(models.User) error) error
+}
+
+// MembersIOHandler обрабатывает массовый импорт и экспорт участников
+type MembersIOHandler struct {
+	service MembersIOService
+}
+
+// NewMembersIOHandler создает новый обработчик импорта и экспорта
+func NewMembersIOHandler(service MembersIOService) *MembersIOHandler {
+	return &MembersIOHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты импорта и экспорта. Доступ есть только у администраторов и сервисов.
+func (h *MembersIOHandler) RegisterRoutes(r *mux.Router) {
+	s := r.PathPrefix("/groups/{gymId}/members").Subrouter()
+	s.Use(middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService))
+
+	s.HandleFunc("/import", h.ImportMembers).Methods("POST")
+	s.HandleFunc("/export", h.ExportMembers).Methods("GET")
+}
+
+// csvColumns - колонки CSV импорта и экспорта
+var csvColumns = []string{"id", "email", "first_name", "last_name", "status"}
+
+// ImportMembers обрабатывает импорт участников из CSV или JSON.
+// Параметр dry_run=true проверяет импорт без сохранения.
+func (h *MembersIOHandler) ImportMembers(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+	gymID := mux.Vars(r)["gymId"]
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+
+	var rows []models.ImportMemberRow
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		rows, err = parseCSVRows(body)
+	case "application/json", "":
+		err = json.NewDecoder(body).Decode(&rows)
+	default:
+		httputil.RespondWithError(w, http.StatusUnsupportedMediaType, "Поддерживаются text/csv и application/json")
+		return
+	}
+	if err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Недопустимое тело запроса: %v", err))
+		return
+	}
+
+	report, err := h.service.ImportMembers(r.Context(), actor, gymID, rows, dryRun)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка импорта участников")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, report)
+}
+
+// parseCSVRows разбирает CSV с заголовком. Порядок колонок произвольный, status необязателен.
+func parseCSVRows(r io.Reader) ([]models.ImportMemberRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("требуется строка заголовка")
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range csvColumns[:4] {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("нет колонки %s", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := index[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var rows []models.ImportMemberRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, models.ImportMemberRow{
+			ID:        field(record, "id"),
+			Email:     field(record, "email"),
+			FirstName: field(record, "first_name"),
+			LastName:  field(record, "last_name"),
+			Status:    models.ActivityStatus(field(record, "status")),
+		})
+	}
+
+	return rows, nil
+}
+
+// ExportMembers обрабатывает потоковый экспорт участников в формате csv, json или ndjson
+func (h *MembersIOHandler) ExportMembers(w http.ResponseWriter, r *http.Request) {
+	gymID := mux.Vars(r)["gymId"]
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+
+	var exporter memberExporter
+	switch format {
+	case "csv":
+		exporter = &csvExporter{}
+	case "json":
+		exporter = &jsonExporter{}
+	case "ndjson":
+		exporter = &ndjsonExporter{}
+	default:
+		httputil.RespondWithError(w, http.StatusBadRequest, "Параметр format должен быть csv, json или ndjson")
+		return
+	}
+
+	// Экспорт больших залов может длиться дольше WriteTimeout сервера
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	buf := bufio.NewWriter(w)
+	started := false
+	err := h.service.ExportMembers(r.Context(), gymID, func(user models.User) error {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", exporter.contentType())
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="members-%s.%s"`, gymID, format))
+			w.WriteHeader(http.StatusOK)
+			if err := exporter.begin(buf); err != nil {
+				return err
+			}
+		}
+		return exporter.write(buf, user)
+	})
+
+	// Пока ничего не отправлено, можно ответить ошибкой
+	if err != nil && !started {
+		respondWithServiceError(w, err, "Ошибка экспорта участников")
+		return
+	}
+	if err != nil {
+		log.Printf("Экспорт участников зала %s прерван: %v", gymID, err)
+		return
+	}
+
+	if !started {
+		w.Header().Set("Content-Type", exporter.contentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="members-%s.%s"`, gymID, format))
+		w.WriteHeader(http.StatusOK)
+		exporter.begin(buf)
+	}
+	exporter.end(buf)
+	buf.Flush()
+}
+
+// memberExporter записывает участников в формате экспорта
+type memberExporter interface {
+	contentType() string
+	begin(w io.Writer) error
+	write(w io.Writer, user models.User) error
+	end(w io.Writer) error
+}
+
+// csvExporter записывает участников в CSV
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func (e *csvExporter) contentType() string { return "text/csv; charset=utf-8" }
+
+func (e *csvExporter) begin(w io.Writer) error {
+	e.writer = csv.NewWriter(w)
+	return e.writer.Write(append(csvColumns, "created_at", "updated_at"))
+}
+
+func (e *csvExporter) write(w io.Writer, user models.User) error {
+	return e.writer.Write([]string{
+		user.ID, user.Email, user.FirstName, user.LastName, string(user.Status),
+		user.CreatedAt.Format(time.RFC3339), user.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (e *csvExporter) end(w io.Writer) error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonExporter записывает участников в JSON в формате ответа GET /groups/{gymId}/members
+type jsonExporter struct {
+	count int
+}
+
+func (e *jsonExporter) contentType() string { return "application/json" }
+
+func (e *jsonExporter) begin(w io.Writer) error {
+	_, err := io.WriteString(w, `{"members":[`)
+	return err
+}
+
+func (e *jsonExporter) write(w io.Writer, user models.User) error {
+	if e.count > 0 {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	return json.NewEncoder(w).Encode(user)
+}
+
+func (e *jsonExporter) end(w io.Writer) error {
+	_, err := io.WriteString(w, "]}\n")
+	return err
+}
+
+// ndjsonExporter записывает участников по одному JSON-объекту в строке
+type ndjsonExporter struct{}
+
+func (e *ndjsonExporter) contentType() string { return "application/x-ndjson" }
+
+func (e *ndjsonExporter) begin(w io.Writer) error { return nil }
+
+func (e *ndjsonExporter) write(w io.Writer, user models.User) error {
+	return json.NewEncoder(w).Encode(user)
+}
+
+func (e *ndjsonExporter) end(w io.Writer) error { return nil }
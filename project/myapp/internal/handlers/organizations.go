@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// OrganizationService определяет интерфейс управления организациями
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, req models.OrganizationRequest) (models.Organization, error)
+	ListOrganizations(ctx context.Context) ([]models.Organization, error)
+}
+
+// OrganizationHandler обрабатывает HTTP-запросы организаций
+type OrganizationHandler struct {
+	service OrganizationService
+}
+
+// NewOrganizationHandler создает новый обработчик организаций
+func NewOrganizationHandler(service OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты организаций. Пользователь видит только свою
+// организацию, создавать организации может только администратор платформы.
+func (h *OrganizationHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/organizations", h.ListOrganizations).Methods("GET")
+	r.Handle("/organizations", middleware.RequireRole(middleware.RolePlatformAdmin)(http.HandlerFunc(h.CreateOrganization))).Methods("POST")
+}
+
+// ListOrganizations обрабатывает получение списка организаций
+func (h *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.service.ListOrganizations(r.Context())
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения организаций")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, orgs)
+}
+
+// CreateOrganization обрабатывает создание организации
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req models.OrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	org, err := h.service.CreateOrganization(r.Context(), req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка создания организации")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, org)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"myapp/internal/models"
+)
+
+// pageParams возвращает курсор и размер страницы из параметров cursor и limit.
+// Нулевой размер означает размер по умолчанию.
+func pageParams(r *http.Request) (string, int) {
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	return query.Get("cursor"), limit
+}
+
+// isPageRequest сообщает, запрошена ли постраничная выдача списка
+func isPageRequest(r *http.Request) bool {
+	query := r.URL.Query()
+	return query.Has("cursor") || query.Has("limit")
+}
+
+// pageLinks строит ссылки на соседние страницы: тот же запрос с другим курсором
+func pageLinks(r *http.Request, next, prev string) models.PageLinks {
+	return models.PageLinks{
+		Next: pageLink(r, next),
+		Prev: pageLink(r, prev),
+	}
+}
+
+func pageLink(r *http.Request, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	link := *r.URL
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// PostService определяет интерфейс ленты публикаций группы
+type PostService interface {
+	ListPosts(ctx context.Context, actor models.Actor, gymID, cursor string, limit int) (models.PostsPage, error)
+	CreatePost(ctx context.Context, actor models.Actor, gymID string, req models.PostRequest) (models.Post, error)
+	UpdatePost(ctx context.Context, actor models.Actor, gymID, postID string, req models.PostRequest) (models.Post, error)
+	DeletePost(ctx context.Context, actor models.Actor, gymID, postID string) error
+	CountUnreadPosts(ctx context.Context, actor models.Actor, gymID string) (int, error)
+	MarkPostsRead(ctx context.Context, actor models.Actor, gymID string) error
+}
+
+// PostHandler обрабатывает HTTP-запросы ленты публикаций
+type PostHandler struct {
+	service PostService
+}
+
+// NewPostHandler создает новый обработчик ленты публикаций
+func NewPostHandler(service PostService) *PostHandler {
+	return &PostHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты ленты публикаций
+func (h *PostHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/groups/{gymId}/posts", h.ListPosts).Methods("GET")
+	r.HandleFunc("/groups/{gymId}/posts", h.CreatePost).Methods("POST")
+	r.HandleFunc("/groups/{gymId}/posts/unread", h.CountUnreadPosts).Methods("GET")
+	r.HandleFunc("/groups/{gymId}/posts/read", h.MarkPostsRead).Methods("POST")
+	r.HandleFunc("/groups/{gymId}/posts/{postId}", h.UpdatePost).Methods("PATCH")
+	r.HandleFunc("/groups/{gymId}/posts/{postId}", h.DeletePost).Methods("DELETE")
+}
+
+// ListPosts обрабатывает получение страницы ленты группы
+func (h *PostHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit := pageParams(r)
+
+	page, err := h.service.ListPosts(r.Context(), actor, mux.Vars(r)["gymId"], cursor, limit)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения ленты группы")
+		return
+	}
+	page.Links = pageLinks(r, page.NextCursor, page.PrevCursor)
+
+	respondWithJSON(w, r, http.StatusOK, page)
+}
+
+// CreatePost обрабатывает создание публикации
+func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.PostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	post, err := h.service.CreatePost(r.Context(), actor, mux.Vars(r)["gymId"], req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка создания публикации")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, post)
+}
+
+// UpdatePost обрабатывает изменение публикации
+func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.PostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	post, err := h.service.UpdatePost(r.Context(), actor, vars["gymId"], vars["postId"], req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка изменения публикации")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, post)
+}
+
+// DeletePost обрабатывает удаление публикации
+func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePost(r.Context(), actor, vars["gymId"], vars["postId"]); err != nil {
+		respondWithServiceError(w, err, "Ошибка удаления публикации")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Публикация успешно удалена"})
+}
+
+// CountUnreadPosts обрабатывает получение числа непрочитанных публикаций
+func (h *PostHandler) CountUnreadPosts(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	unread, err := h.service.CountUnreadPosts(r.Context(), actor, mux.Vars(r)["gymId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения непрочитанных публикаций")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]int{"unread": unread})
+}
+
+// MarkPostsRead обрабатывает отметку ленты прочитанной
+func (h *PostHandler) MarkPostsRead(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.MarkPostsRead(r.Context(), actor, mux.Vars(r)["gymId"]); err != nil {
+		respondWithServiceError(w, err, "Ошибка отметки ленты прочитанной")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Лента отмечена прочитанной"})
+}
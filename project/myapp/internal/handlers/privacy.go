@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// PrivacyService определяет интерфейс выгрузки и удаления персональных данных
+type PrivacyService interface {
+	ExportMyData(ctx context.Context, actor models.Actor) (models.DataExport, error)
+	EraseMyData(ctx context.Context, actor models.Actor, req models.EraseRequest) (models.Erasure, error)
+	EraseMemberData(ctx context.Context, actor models.Actor, gymID, userID string, req models.EraseRequest) (models.Erasure, error)
+}
+
+// PrivacyHandler обрабатывает HTTP-запросы персональных данных
+type PrivacyHandler struct {
+	service PrivacyService
+}
+
+// NewPrivacyHandler создает новый обработчик персональных данных
+func NewPrivacyHandler(service PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты персональных данных. Выгрузить и удалить
+// свои данные может любой пользователь, данные участника зала - администраторы и сервисы.
+func (h *PrivacyHandler) RegisterRoutes(r *mux.Router) {
+	requireAdmin := middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService)
+
+	r.HandleFunc("/me/data-export", h.ExportMyData).Methods("GET")
+	r.HandleFunc("/me", h.EraseMyData).Methods("DELETE")
+	r.Handle("/gyms/{gymId}/members/{userId}/data", requireAdmin(http.HandlerFunc(h.EraseMemberData))).Methods("DELETE")
+}
+
+// ExportMyData обрабатывает выгрузку данных вызывающего.
+// По умолчанию возвращает JSON, при format=zip или Accept: application/zip - ZIP-архив.
+func (h *PrivacyHandler) ExportMyData(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "application/zip") {
+		format = "zip"
+	}
+	if format != "" && format != "json" && format != "zip" {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Формат выгрузки должен быть json или zip")
+		return
+	}
+
+	export, err := h.service.ExportMyData(r.Context(), actor)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка выгрузки данных")
+		return
+	}
+
+	if format != "zip" {
+		respondWithJSON(w, r, http.StatusOK, export)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="data-export-%s.zip"`, actor.UserID))
+	w.WriteHeader(http.StatusOK)
+	if err := writeExportArchive(w, export); err != nil {
+		// Заголовки уже отправлены - остается только прервать архив
+		log.Printf("Ошибка записи архива данных пользователя %s: %v", actor.UserID, err)
+	}
+}
+
+// writeExportArchive записывает выгрузку в ZIP-архив: по файлу на каждую таблицу
+func writeExportArchive(w io.Writer, export models.DataExport) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name  string
+		value interface{}
+	}{
+		{"user.json", export.User},
+		{"memberships.json", export.Memberships},
+		{"status_history.json", export.StatusHistory},
+		{"visits.json", export.Visits},
+	}
+
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.value); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// EraseMyData обрабатывает удаление данных вызывающего
+func (h *PrivacyHandler) EraseMyData(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeEraseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	erasure, err := h.service.EraseMyData(r.Context(), actor, req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка удаления данных")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, erasure)
+}
+
+// EraseMemberData обрабатывает удаление данных участника зала администратором
+func (h *PrivacyHandler) EraseMemberData(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeEraseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	erasure, err := h.service.EraseMemberData(r.Context(), actor, vars["gymId"], vars["userId"], req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка удаления данных участника")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, erasure)
+}
+
+// decodeEraseRequest разбирает необязательное тело запроса на удаление данных
+func decodeEraseRequest(w http.ResponseWriter, r *http.Request) (models.EraseRequest, bool) {
+	var req models.EraseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return req, false
+	}
+	return req, true
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"myapp/internal/events"
+	"myapp/internal/models"
+	"myapp/internal/stream"
+	"myapp/pkg/auth"
+	httputil "myapp/pkg/http"
+)
+
+// heartbeatInterval - интервал отправки heartbeat в открытые потоки
+const heartbeatInterval = 15 * time.Second
+
+// StreamHub определяет интерфейс рассылки событий групп
+type StreamHub interface {
+	Subscribe(gymID, lastEventID string) (*stream.Subscription, []stream.Event, bool)
+	Unsubscribe(sub *stream.Subscription)
+}
+
+// StreamHandler обрабатывает потоки активности групп по SSE и WebSocket
+type StreamHandler struct {
+	service  Service
+	hub      StreamHub
+	upgrader websocket.Upgrader
+}
+
+// NewStreamHandler создает новый обработчик потоков
+func NewStreamHandler(service Service, hub StreamHub) *StreamHandler {
+	return &StreamHandler{
+		service: service,
+		hub:     hub,
+		upgrader: websocket.Upgrader{
+			// Клиенты аутентифицируются заголовком Authorization, а не cookie
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// RegisterRoutes регистрирует маршруты потоков
+func (h *StreamHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/groups/{gymId}/stream", h.StreamSSE).Methods("GET")
+	r.HandleFunc("/groups/{gymId}/ws", h.StreamWebSocket).Methods("GET")
+}
+
+// streamMessage представляет событие потока для клиента
+type streamMessage struct {
+	ID   string          `json:"id,omitempty"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// streamMember - данные события участника для клиентов потока. Поток видят все
+// участники группы, поэтому причина смены статуса в него не передается.
+type streamMember struct {
+	UserID         string                `json:"user_id"`
+	GymID          string                `json:"gym_id"`
+	Status         models.ActivityStatus `json:"status,omitempty"`
+	PreviousStatus models.ActivityStatus `json:"previous_status,omitempty"`
+	OccurredAt     time.Time             `json:"occurred_at"`
+}
+
+// streamData возвращает данные события для клиента потока
+func streamData(event stream.Event) (json.RawMessage, error) {
+	payload, err := event.DecodeMember()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(streamMember{
+		UserID:         payload.UserID,
+		GymID:          payload.GymID,
+		Status:         payload.Status,
+		PreviousStatus: payload.PreviousStatus,
+		OccurredAt:     payload.OccurredAt,
+	})
+}
+
+// revokes сообщает, что событие лишает пользователя userID участия в группе
+// и его поток нужно закрыть
+func revokes(event stream.Event, userID string) bool {
+	payload, err := event.DecodeMember()
+	if err != nil || payload.UserID != userID {
+		return false
+	}
+	return event.Type == events.MemberLeft || (payload.Status != "" && !payload.Status.IsMember())
+}
+
+// authorize проверяет, что пользователь из токена состоит в группе зала,
+// и возвращает ID зала и пользователя
+func (h *StreamHandler) authorize(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	gymID := mux.Vars(r)["gymId"]
+	if gymID == "" {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Требуется ID зала")
+		return "", "", false
+	}
+
+	userID, err := auth.GetUserIDFromToken(r)
+	if err != nil {
+		httputil.RespondWithError(w, http.StatusUnauthorized, "Недействительный токен")
+		return "", "", false
+	}
+
+	status, err := h.service.GetUserStatus(r.Context(), userID, gymID)
+	if err != nil && StatusFromError(err) != http.StatusNotFound {
+		respondWithServiceError(w, err, "Ошибка проверки участия в группе")
+		return "", "", false
+	}
+	if err != nil || !status.IsMember() {
+		httputil.RespondWithError(w, http.StatusForbidden, "Пользователь не состоит в группе зала")
+		return "", "", false
+	}
+
+	return gymID, userID, true
+}
+
+// lastEventID извлекает ID последнего полученного события из заголовка или параметра запроса
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("last_event_id")
+}
+
+// StreamSSE обрабатывает поток событий группы по Server-Sent Events
+func (h *StreamHandler) StreamSSE(w http.ResponseWriter, r *http.Request) {
+	gymID, userID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// Поток живет дольше WriteTimeout сервера
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		httputil.RespondWithError(w, http.StatusInternalServerError, "Потоковая передача не поддерживается")
+		return
+	}
+
+	sub, missed, complete := h.hub.Subscribe(gymID, lastEventID(r))
+	defer h.hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", 3000)
+	if !complete {
+		// Часть событий потеряна - клиенту нужно заново загрузить список участников
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range missed {
+		if err := writeSSEEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event := <-sub.Events():
+			// Участник, покинувший группу или заблокированный, перестает получать ее события;
+			// при переподключении authorize отклонит запрос
+			if revokes(event, userID) {
+				return
+			}
+			if err := writeSSEEvent(w, event); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeSSEEvent записывает событие в формате SSE
+func writeSSEEvent(w http.ResponseWriter, event stream.Event) error {
+	data, err := streamData(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.StreamID, event.Type, data)
+	return err
+}
+
+// StreamWebSocket обрабатывает поток событий группы по WebSocket
+func (h *StreamHandler) StreamWebSocket(w http.ResponseWriter, r *http.Request) {
+	gymID, userID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade уже отправил ответ с ошибкой
+		return
+	}
+	defer conn.Close()
+
+	sub, missed, complete := h.hub.Subscribe(gymID, lastEventID(r))
+	defer h.hub.Unsubscribe(sub)
+
+	// Читаем входящие кадры, чтобы обрабатывать pong и закрытие соединения
+	closed := make(chan struct{})
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(msg streamMessage) error {
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return conn.WriteJSON(msg)
+	}
+	writeEvent := func(event stream.Event) error {
+		data, err := streamData(event)
+		if err != nil {
+			return err
+		}
+		return write(streamMessage{ID: event.StreamID, Type: string(event.Type), Data: data})
+	}
+
+	if !complete {
+		if err := write(streamMessage{Type: "reset"}); err != nil {
+			return
+		}
+	}
+	for _, event := range missed {
+		if err := writeEvent(event); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-sub.Done():
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"),
+				time.Now().Add(time.Second))
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		case event := <-sub.Events():
+			if revokes(event, userID) {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "membership ended"),
+					time.Now().Add(time.Second))
+				return
+			}
+			if err := writeEvent(event); err != nil {
+				return
+			}
+		}
+	}
+}
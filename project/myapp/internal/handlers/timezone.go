@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"myapp/internal/middleware"
+	httputil "myapp/pkg/http"
+)
+
+// timeType - тип меток времени, которые переводятся в часовой пояс ответа
+var timeType = reflect.TypeOf(time.Time{})
+
+// respondWithJSON отправляет JSON-ответ, переводя метки времени в часовой пояс из
+// параметра tz запроса. Без параметра метки времени выводятся в UTC, как хранятся.
+func respondWithJSON(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	if loc, ok := middleware.GetLocation(r.Context()); ok && payload != nil {
+		payload = inLocation(reflect.ValueOf(payload), loc).Interface()
+	}
+	httputil.RespondWithJSON(w, code, payload)
+}
+
+// inLocation возвращает копию значения, в которой все метки времени переведены в
+// часовой пояс loc. Исходное значение не изменяется, неэкспортируемые поля
+// копируются как есть.
+func inLocation(v reflect.Value, loc *time.Location) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	if v.Type() == timeType {
+		// Нулевое время остается в UTC, иначе оно выводится с историческим смещением пояса
+		if t := v.Interface().(time.Time); !t.IsZero() {
+			return reflect.ValueOf(t.In(loc))
+		}
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		result := reflect.New(v.Type().Elem())
+		result.Elem().Set(inLocation(v.Elem(), loc))
+		return result
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		result := reflect.New(v.Type()).Elem()
+		result.Set(inLocation(v.Elem(), loc))
+		return result
+	case reflect.Struct:
+		result := reflect.New(v.Type()).Elem()
+		result.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := result.Field(i); field.CanSet() {
+				field.Set(inLocation(v.Field(i), loc))
+			}
+		}
+		return result
+	case reflect.Slice:
+		// Байтовые срезы (json.RawMessage и т.п.) не содержат меток времени
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(inLocation(v.Index(i), loc))
+		}
+		return result
+	case reflect.Array:
+		result := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(inLocation(v.Index(i), loc))
+		}
+		return result
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), inLocation(iter.Value(), loc))
+		}
+		return result
+	}
+	return v
+}
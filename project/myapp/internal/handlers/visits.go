@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// VisitService определяет интерфейс посещений и рейтинга группы
+type VisitService interface {
+	CheckIn(ctx context.Context, actor models.Actor, gymID string, req models.CheckInRequest) (models.Visit, error)
+	GetLeaderboard(ctx context.Context, actor models.Actor, gymID string, period models.LeaderboardPeriod, limit int) (models.Leaderboard, error)
+}
+
+// VisitHandler обрабатывает HTTP-запросы посещений
+type VisitHandler struct {
+	service VisitService
+}
+
+// NewVisitHandler создает новый обработчик посещений
+func NewVisitHandler(service VisitService) *VisitHandler {
+	return &VisitHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты посещений и рейтинга
+func (h *VisitHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/groups/{gymId}/visits", h.CheckIn).Methods("POST")
+	r.HandleFunc("/groups/{gymId}/leaderboard", h.GetLeaderboard).Methods("GET")
+}
+
+// CheckIn обрабатывает отметку посещения зала
+func (h *VisitHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CheckInRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+			return
+		}
+	}
+
+	visit, err := h.service.CheckIn(r.Context(), actor, mux.Vars(r)["gymId"], req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка отметки посещения")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, visit)
+}
+
+// GetLeaderboard обрабатывает получение рейтинга группы
+func (h *VisitHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	period := models.LeaderboardPeriod(query.Get("period"))
+
+	board, err := h.service.GetLeaderboard(r.Context(), actor, mux.Vars(r)["gymId"], period, limit)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения рейтинга")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, board)
+}
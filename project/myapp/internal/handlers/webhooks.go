@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/middleware"
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// WebhookService определяет интерфейс управления вебхуками залов
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, gymID string, req models.WebhookRequest) (models.Webhook, error)
+	ListWebhooks(ctx context.Context, gymID string) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, gymID, webhookID string) (models.Webhook, error)
+	UpdateWebhook(ctx context.Context, gymID, webhookID string, req models.WebhookRequest) (models.Webhook, error)
+	DeleteWebhook(ctx context.Context, gymID, webhookID string) error
+	ListDeliveries(ctx context.Context, gymID, webhookID string, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, gymID, webhookID, deliveryID string) (models.WebhookDelivery, error)
+}
+
+// WebhookHandler обрабатывает HTTP-запросы управления вебхуками
+type WebhookHandler struct {
+	service WebhookService
+}
+
+// NewWebhookHandler создает новый обработчик вебхуков
+func NewWebhookHandler(service WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты вебхуков. Доступ есть только у администраторов.
+func (h *WebhookHandler) RegisterRoutes(r *mux.Router) {
+	s := r.PathPrefix("/gyms/{gymId}/webhooks").Subrouter()
+	s.Use(middleware.RequireRole(middleware.RoleAdmin, middleware.RoleService))
+
+	s.HandleFunc("", h.CreateWebhook).Methods("POST")
+	s.HandleFunc("", h.ListWebhooks).Methods("GET")
+	s.HandleFunc("/{webhookId}", h.GetWebhook).Methods("GET")
+	s.HandleFunc("/{webhookId}", h.UpdateWebhook).Methods("PUT")
+	s.HandleFunc("/{webhookId}", h.DeleteWebhook).Methods("DELETE")
+	s.HandleFunc("/{webhookId}/deliveries", h.ListDeliveries).Methods("GET")
+	s.HandleFunc("/{webhookId}/deliveries/{deliveryId}/redeliver", h.Redeliver).Methods("POST")
+}
+
+// CreateWebhook обрабатывает создание вебхука. Секрет возвращается только в этом ответе.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	gymID := mux.Vars(r)["gymId"]
+
+	var req models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(r.Context(), gymID, req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка создания вебхука")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, webhook)
+}
+
+// ListWebhooks обрабатывает получение вебхуков зала
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	gymID := mux.Vars(r)["gymId"]
+
+	webhooks, err := h.service.ListWebhooks(r.Context(), gymID)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения вебхуков")
+		return
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string][]models.Webhook{"webhooks": webhooks})
+}
+
+// GetWebhook обрабатывает получение вебхука
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	webhook, err := h.service.GetWebhook(r.Context(), vars["gymId"], vars["webhookId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения вебхука")
+		return
+	}
+
+	webhook.Secret = ""
+	respondWithJSON(w, r, http.StatusOK, webhook)
+}
+
+// UpdateWebhook обрабатывает изменение вебхука
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(r.Context(), vars["gymId"], vars["webhookId"], req)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка изменения вебхука")
+		return
+	}
+
+	webhook.Secret = ""
+	respondWithJSON(w, r, http.StatusOK, webhook)
+}
+
+// DeleteWebhook обрабатывает удаление вебхука
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := h.service.DeleteWebhook(r.Context(), vars["gymId"], vars["webhookId"]); err != nil {
+		respondWithServiceError(w, err, "Ошибка удаления вебхука")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Вебхук успешно удален"})
+}
+
+// ListDeliveries обрабатывает получение журнала доставок вебхука
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), vars["gymId"], vars["webhookId"], limit)
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка получения доставок вебхука")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, map[string][]models.WebhookDelivery{"deliveries": deliveries})
+}
+
+// Redeliver обрабатывает ручную повторную отправку доставки
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	delivery, err := h.service.Redeliver(r.Context(), vars["gymId"], vars["webhookId"], vars["deliveryId"])
+	if err != nil {
+		respondWithServiceError(w, err, "Ошибка повторной отправки доставки")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusAccepted, delivery)
+}
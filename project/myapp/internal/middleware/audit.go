@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"myapp/internal/audit"
+	"myapp/internal/models"
+)
+
+// RequestIDHeader - заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength - максимальная длина идентификатора запроса, принятого от клиента
+const maxRequestIDLength = 64
+
+// requestIDKey - ключ контекста для идентификатора запроса
+type requestIDKey struct{}
+
+// RequestID - промежуточное ПО, которое присваивает запросу идентификатор.
+// Идентификатор из заголовка X-Request-ID сохраняется, иначе генерируется новый;
+// он возвращается в ответе и попадает в журнал аудита.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = NewRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID)))
+	})
+}
+
+// GetRequestID извлекает идентификатор запроса из контекста
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// NewRequestID генерирует случайный идентификатор запроса
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// AuditRecorder определяет интерфейс записи в журнал аудита
+type AuditRecorder interface {
+	Record(ctx context.Context, entry models.AuditEntry) error
+	GymOrganization(ctx context.Context, gymID string) (string, error)
+}
+
+// auditTimeout ограничивает запись в журнал, которая выполняется и после отмены запроса
+const auditTimeout = 5 * time.Second
+
+// Audit - промежуточное ПО, которое записывает в журнал аудита каждый изменяющий вызов:
+// кто его сделал, маршрут, идентификаторы из пути, значения до и после изменения,
+// сообщенные сервисом, и результат. Подключается после JWTAuth.
+func Audit(recorder AuditRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r.Context())
+			if !ok || !isMutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Организация определяется до вызова: вызов может удалить зал
+			targets := models.AuditTargets(mux.Vars(r))
+			orgID := auditOrganization(r.Context(), recorder, claims, targets["gymId"])
+
+			ctx, changes := audit.WithChanges(r.Context())
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			RecordAuditEntry(r.Context(), recorder, claims, changes, models.AuditEntry{
+				RequestID:      GetRequestID(r.Context()),
+				OrganizationID: orgID,
+				Method:         r.Method,
+				Route:          routeTemplate(r),
+				TargetIDs:      targets,
+				StatusCode:     sw.status,
+				Outcome:        audit.Outcome(sw.status),
+			})
+		})
+	}
+}
+
+// RecordAuditEntry дополняет запись журнала вызывающей стороной, организацией,
+// залом и пользователем из идентификаторов вызова и изменением, сообщенным сервисом,
+// и сохраняет ее. Используется HTTP- и gRPC-вызовами.
+func RecordAuditEntry(ctx context.Context, recorder AuditRecorder, claims Claims, changes *audit.Changes, entry models.AuditEntry) {
+	entry.ActorID = claims.UserID
+	entry.ActorRole = claims.Role
+	if gymID := entry.TargetIDs["gymId"]; gymID != "" {
+		entry.GymID = &gymID
+	}
+	if userID := entry.TargetIDs["userId"]; userID != "" {
+		entry.TargetUserID = &userID
+	}
+	if err := changes.Apply(&entry); err != nil {
+		log.Printf("Ошибка сериализации изменения для журнала аудита: %v", err)
+	}
+	if entry.OrganizationID == nil {
+		var gymID string
+		if entry.GymID != nil {
+			gymID = *entry.GymID
+		}
+		entry.OrganizationID = auditOrganization(ctx, recorder, claims, gymID)
+	}
+
+	// Вызов уже завершен: запись не должна зависеть от отключения клиента
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditTimeout)
+	defer cancel()
+	if err := recorder.Record(recordCtx, entry); err != nil {
+		log.Printf("Ошибка записи в журнал аудита %s %s: %v", entry.Method, entry.Route, err)
+	}
+}
+
+// auditOrganization возвращает организацию записи журнала: организацию из токена,
+// а у вызывающих без организации (внутренний сервис, администратор платформы) -
+// организацию зала, чтобы администратор организации видел их изменения
+func auditOrganization(ctx context.Context, recorder AuditRecorder, claims Claims, gymID string) *string {
+	if claims.TenantID != "" {
+		return &claims.TenantID
+	}
+	if gymID == "" {
+		return nil
+	}
+
+	orgID, err := recorder.GymOrganization(ctx, gymID)
+	if err != nil {
+		log.Printf("Ошибка определения организации зала %s для журнала аудита: %v", gymID, err)
+		return nil
+	}
+	if orgID == "" {
+		return nil
+	}
+	return &orgID
+}
+
+// isMutating сообщает, изменяет ли вызов с методом method данные
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// routeTemplate возвращает шаблон маршрута, например /groups/{gymId}/members,
+// или путь запроса, если маршрут неизвестен
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.Path
+}
+
+// statusWriter запоминает код ответа
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader запоминает первый код ответа
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write отправляет тело ответа; без явного WriteHeader код ответа 200
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"myapp/internal/models"
+	httputil "myapp/pkg/http"
+)
+
+// IdempotencyKeyHeader - заголовок с ключом идемпотентности запроса
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader отмечает ответ, повторенный из сохраненного
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// Ограничения запросов с ключом идемпотентности
+const (
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodySize   = 10 << 20    // как у импорта участников, самого большого запроса
+	idempotencyLease        = time.Minute // время, на которое запрос захватывает ключ
+	idempotencyRenewal      = idempotencyLease / 3
+	idempotencyTimeout      = 5 * time.Second
+)
+
+// replayedHeaders - заголовки ответа, которые сохраняются и повторяются вместе с телом,
+// чтобы повтор, например, вернул ETag для следующего If-Match
+var replayedHeaders = []string{"ETag", "Last-Modified", "Location"}
+
+// IdempotencyStore определяет интерфейс хранилища ключей идемпотентности
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error
+	ExtendIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error
+}
+
+// Idempotency - промежуточное ПО, которое обрабатывает заголовок Idempotency-Key
+// у запросов POST и PUT. Ответ на запрос сохраняется на время ttl, и повтор с тем же
+// ключом и тем же телом получает сохраненный ответ без повторного выполнения.
+// Тот же ключ с другим запросом отклоняется кодом 422, а повтор, пришедший до
+// завершения первого запроса, - кодом 409. Ключи разделены по вызывающим сторонам.
+// Ответы 5xx не сохраняются, чтобы клиент мог повторить запрос. Пока запрос выполняется,
+// захват ключа продлевается, поэтому долгий запрос не выполнится повторно.
+// Подключается после JWTAuth и до Audit, чтобы повторы не попадали в журнал аудита.
+func Idempotency(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			claims, ok := GetClaims(r.Context())
+			if key == "" || !ok || (r.Method != http.MethodPost && r.Method != http.MethodPut) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				httputil.RespondWithError(w, http.StatusBadRequest, "Слишком длинный ключ идемпотентности")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				httputil.RespondWithError(w, http.StatusRequestEntityTooLarge, "Слишком большое тело запроса")
+				return
+			}
+			if err != nil {
+				httputil.RespondWithError(w, http.StatusBadRequest, "Не удалось прочитать тело запроса")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now().UTC()
+			record := models.IdempotencyRecord{
+				ActorID:     claims.UserID,
+				Key:         key,
+				RequestHash: requestHash(r, body),
+				LockedUntil: now.Add(idempotencyLease),
+				ExpiresAt:   now.Add(ttl),
+			}
+
+			existing, reserved, err := store.ReserveIdempotencyKey(r.Context(), record)
+			if err != nil {
+				log.Printf("Ошибка захвата ключа идемпотентности: %v", err)
+				httputil.RespondWithError(w, http.StatusInternalServerError, "Ошибка обработки ключа идемпотентности")
+				return
+			}
+			if !reserved {
+				replay(w, existing, record.RequestHash)
+				return
+			}
+
+			stopRenewal := renewLease(r.Context(), store, record)
+			rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+			stopRenewal()
+
+			// Запрос уже выполнен: результат сохраняется и после отключения клиента
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyTimeout)
+			defer cancel()
+
+			if rw.status >= http.StatusInternalServerError {
+				if err := store.ReleaseIdempotencyKey(ctx, record); err != nil {
+					log.Printf("Ошибка освобождения ключа идемпотентности: %v", err)
+				}
+				return
+			}
+
+			contentType := rw.Header().Get("Content-Type")
+			record.StatusCode = &rw.status
+			record.ContentType = &contentType
+			record.ResponseBody = rw.body.Bytes()
+			for _, name := range replayedHeaders {
+				if value := rw.Header().Get(name); value != "" {
+					if record.ResponseHeaders == nil {
+						record.ResponseHeaders = models.ResponseHeaders{}
+					}
+					record.ResponseHeaders[name] = value
+				}
+			}
+			if err := store.CompleteIdempotencyKey(ctx, record); err != nil {
+				log.Printf("Ошибка сохранения ответа по ключу идемпотентности: %v", err)
+			}
+		})
+	}
+}
+
+// renewLease продлевает захват ключа, пока не будет вызвана возвращенная функция
+func renewLease(ctx context.Context, store IdempotencyStore, record models.IdempotencyRecord) (stop func()) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(idempotencyRenewal)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				record.LockedUntil = time.Now().UTC().Add(idempotencyLease)
+				extendCtx, cancelExtend := context.WithTimeout(ctx, idempotencyTimeout)
+				if err := store.ExtendIdempotencyKey(extendCtx, record); err != nil {
+					log.Printf("Ошибка продления ключа идемпотентности: %v", err)
+				}
+				cancelExtend()
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// replay отвечает на повтор запроса с занятым ключом
+func replay(w http.ResponseWriter, existing models.IdempotencyRecord, requestHash string) {
+	switch {
+	case existing.RequestHash != requestHash:
+		httputil.RespondWithError(w, http.StatusUnprocessableEntity, "Ключ идемпотентности уже использован с другим запросом")
+	case !existing.Completed():
+		httputil.RespondWithError(w, http.StatusConflict, "Запрос с этим ключом идемпотентности еще выполняется")
+	default:
+		if existing.ContentType != nil && *existing.ContentType != "" {
+			w.Header().Set("Content-Type", *existing.ContentType)
+		}
+		for name, value := range existing.ResponseHeaders {
+			w.Header().Set(name, value)
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(*existing.StatusCode)
+		w.Write(existing.ResponseBody)
+	}
+}
+
+// requestHash вычисляет хэш метода, пути, параметров и тела запроса:
+// тот же ключ для другого маршрута считается другим запросом
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	for _, part := range []string{r.Method, r.URL.Path, r.URL.RawQuery} {
+		io.WriteString(h, strconv.Itoa(len(part)))
+		io.WriteString(h, ":")
+		io.WriteString(h, part)
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter передает ответ клиенту и запоминает код и тело для сохранения
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader запоминает первый код ответа
+func (w *recordingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write передает тело ответа и копирует его
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
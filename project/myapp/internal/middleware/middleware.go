@@ -2,18 +2,24 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"myapp/internal/models"
+	"myapp/internal/tenant"
 	httputil "myapp/pkg/http"
 )
 
 // userIDKey - ключ контекста для ID пользователя
 type userIDKey struct{}
 
+// claimsKey - ключ контекста для утверждений токена
+type claimsKey struct{}
+
 // Logger - промежуточное ПО, которое логирует запросы
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -34,6 +40,58 @@ func JSONContentType(next http.Handler) http.Handler {
 	})
 }
 
+// Роли, передаваемые в утверждении role токена
+const (
+	RoleUser          = models.RoleUser          // обычный пользователь
+	RoleAdmin         = models.RoleAdmin         // администратор организации
+	RoleService       = models.RoleService       // внутренний сервис
+	RolePlatformAdmin = models.RolePlatformAdmin // администратор платформы
+)
+
+// Claims содержит данные, извлеченные из JWT токена
+type Claims struct {
+	UserID   string // ID пользователя или имя сервиса (утверждение sub)
+	Role     string // роль вызывающей стороны (утверждение role)
+	TenantID string // организация вызывающей стороны (утверждение tenant_id)
+}
+
+// ParseToken проверяет JWT токен и извлекает из него утверждения
+func ParseToken(secret, tokenString string) (Claims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Проверяем алгоритм
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return Claims{}, errors.New("недействительный токен")
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return Claims{}, errors.New("недействительные утверждения токена")
+	}
+
+	userID, ok := mapClaims["sub"].(string)
+	if !ok || userID == "" {
+		return Claims{}, errors.New("недействительный ID пользователя в токене")
+	}
+
+	role, _ := mapClaims["role"].(string)
+	if role == "" {
+		role = RoleUser
+	}
+
+	// Без организации работают только администраторы платформы и внутренние сервисы
+	tenantID, _ := mapClaims["tenant_id"].(string)
+	if tenantID == "" && role != RolePlatformAdmin && role != RoleService {
+		return Claims{}, errors.New("в токене не указана организация")
+	}
+
+	return Claims{UserID: userID, Role: role, TenantID: tenantID}, nil
+}
+
 // JWTAuth - промежуточное ПО, которое проверяет JWT токены
 func JWTAuth(secret string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -46,50 +104,89 @@ func JWTAuth(secret string) func(http.Handler) http.Handler {
 			}
 
 			// Токен должен быть в формате "Bearer <token>"
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
+			tokenString, ok := BearerToken(authHeader)
+			if !ok {
 				httputil.RespondWithError(w, http.StatusUnauthorized, "Недопустимый формат авторизации")
 				return
 			}
 
-			tokenString := parts[1]
-
 			// Парсим и проверяем токен
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				// Проверяем алгоритм
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return []byte(secret), nil
-			})
-
-			if err != nil || !token.Valid {
+			claims, err := ParseToken(secret, tokenString)
+			if err != nil {
 				httputil.RespondWithError(w, http.StatusUnauthorized, "Недействительный токен")
 				return
 			}
 
-			// Извлекаем ID пользователя из утверждений (claims)
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
-				httputil.RespondWithError(w, http.StatusUnauthorized, "Недействительные утверждения токена")
-				return
-			}
+			// Добавляем утверждения токена в контекст
+			next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+		})
+	}
+}
 
-			userID, ok := claims["sub"].(string)
-			if !ok || userID == "" {
-				httputil.RespondWithError(w, http.StatusUnauthorized, "Недействительный ID пользователя в токене")
-				return
-			}
+// BearerToken извлекает токен из значения заголовка вида "Bearer <token>"
+func BearerToken(header string) (string, bool) {
+	parts := strings.Split(header, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
 
-			// Добавляем ID пользователя в контекст
-			ctx := context.WithValue(r.Context(), userIDKey{}, userID)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+// WithClaims добавляет утверждения токена в контекст и ограничивает его организацией из токена.
+// Доступ ко всем организациям получают только администраторы платформы и внутренние
+// сервисы без организации в токене.
+func WithClaims(ctx context.Context, claims Claims) context.Context {
+	switch {
+	case claims.TenantID != "":
+		ctx = tenant.WithOrganization(ctx, claims.TenantID)
+	case claims.Role == RolePlatformAdmin || claims.Role == RoleService:
+		ctx = tenant.Unscoped(ctx)
 	}
+	ctx = tenant.WithUser(ctx, claims.UserID)
+	ctx = context.WithValue(ctx, userIDKey{}, claims.UserID)
+	return context.WithValue(ctx, claimsKey{}, claims)
 }
 
 // GetUserID извлекает ID пользователя из контекста
 func GetUserID(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value(userIDKey{}).(string)
 	return userID, ok
-}
\ No newline at end of file
+}
+
+// GetActor возвращает вызывающую сторону по утверждениям токена из контекста
+func GetActor(ctx context.Context) (models.Actor, bool) {
+	claims, ok := GetClaims(ctx)
+	if !ok {
+		return models.Actor{}, false
+	}
+	return models.Actor{UserID: claims.UserID, Role: claims.Role}, true
+}
+
+// GetClaims извлекает утверждения токена из контекста
+func GetClaims(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
+
+// RequireRole - промежуточное ПО, которое пропускает только запросы с одной из указанных ролей.
+// Администратору платформы доступно все, что доступно администратору организации.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r.Context())
+			if !ok {
+				httputil.RespondWithError(w, http.StatusUnauthorized, "Недействительный токен")
+				return
+			}
+
+			for _, role := range roles {
+				if claims.Role == role || (role == RoleAdmin && claims.Role == RolePlatformAdmin) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			httputil.RespondWithError(w, http.StatusForbidden, "Недостаточно прав")
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	httputil "myapp/pkg/http"
+)
+
+// locationKey - ключ контекста для часового пояса ответа
+type locationKey struct{}
+
+// Timezone - промежуточное ПО, которое принимает часовой пояс ответа из параметра
+// запроса tz, например ?tz=Asia/Almaty, и сохраняет его в контексте. Метки времени
+// хранятся и вычисляются в UTC, обработчики переводят их в этот пояс при выводе.
+func Timezone(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("tz")
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, "Неизвестный часовой пояс")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), locationKey{}, loc)))
+	})
+}
+
+// GetLocation извлекает часовой пояс ответа из контекста
+func GetLocation(ctx context.Context) (*time.Location, bool) {
+	loc, ok := ctx.Value(locationKey{}).(*time.Location)
+	return loc, ok
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditOutcome представляет результат записанного в журнал вызова
+type AuditOutcome string
+
+// Константы результата вызова
+const (
+	AuditSuccess AuditOutcome = "success" // вызов завершился успешно
+	AuditFailure AuditOutcome = "failure" // вызов завершился ошибкой
+)
+
+// AuditEntry представляет запись журнала аудита об изменяющем вызове API
+type AuditEntry struct {
+	ID             int64        `json:"id" db:"id"`
+	RequestID      string       `json:"request_id" db:"request_id"`
+	OrganizationID *string      `json:"organization_id,omitempty" db:"organization_id"`
+	ActorID        string       `json:"actor_id" db:"actor_id"`
+	ActorRole      string       `json:"actor_role" db:"actor_role"`
+	Method         string       `json:"method" db:"method"`
+	Route          string       `json:"route" db:"route"`
+	TargetIDs      AuditTargets `json:"target_ids" db:"target_ids"`
+	GymID          *string      `json:"gym_id,omitempty" db:"gym_id"`
+	TargetUserID   *string      `json:"target_user_id,omitempty" db:"target_user_id"`
+	Before         AuditValue   `json:"before,omitempty" db:"before_value"`
+	After          AuditValue   `json:"after,omitempty" db:"after_value"`
+	StatusCode     int          `json:"status_code" db:"status_code"`
+	Outcome        AuditOutcome `json:"outcome" db:"outcome"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+}
+
+// AuditTargets содержит идентификаторы из пути запроса, например gymId и userId.
+// В базе данных хранится как JSONB.
+type AuditTargets map[string]string
+
+// Value реализует driver.Valuer
+func (t AuditTargets) Value() (driver.Value, error) {
+	if t == nil {
+		t = AuditTargets{}
+	}
+	return json.Marshal(t)
+}
+
+// Scan реализует sql.Scanner
+func (t *AuditTargets) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case nil:
+		*t = AuditTargets{}
+		return nil
+	default:
+		return fmt.Errorf("неподдерживаемый тип идентификаторов аудита %T", src)
+	}
+}
+
+// AuditValue содержит значение до или после изменения в виде JSON.
+// Пустое значение хранится в базе данных как NULL.
+type AuditValue json.RawMessage
+
+// MarshalJSON реализует json.Marshaler
+func (v AuditValue) MarshalJSON() ([]byte, error) {
+	if len(v) == 0 {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+// Value реализует driver.Valuer
+func (v AuditValue) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return []byte(v), nil
+}
+
+// Scan реализует sql.Scanner. Байты копируются: драйвер может переиспользовать буфер.
+func (v *AuditValue) Scan(src interface{}) error {
+	switch b := src.(type) {
+	case []byte:
+		*v = append(AuditValue(nil), b...)
+	case string:
+		*v = AuditValue(b)
+	case nil:
+		*v = nil
+	default:
+		return fmt.Errorf("неподдерживаемый тип значения аудита %T", src)
+	}
+	return nil
+}
+
+// AuditChange описывает изменение, которое сервис сообщает журналу аудита
+type AuditChange struct {
+	GymID  string
+	UserID string
+	Before interface{}
+	After  interface{}
+}
+
+// AuditFilter задает условия выборки журнала аудита. Пустые поля не ограничивают выборку.
+// Записи возвращаются от новых к старым, BeforeID продолжает выборку после последней записи.
+type AuditFilter struct {
+	ActorID  string
+	GymID    string
+	From     *time.Time
+	To       *time.Time
+	BeforeID int64
+	Limit    int
+}
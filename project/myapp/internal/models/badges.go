@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// BadgeKind определяет показатель, по которому выдается награда
+type BadgeKind string
+
+// Виды наград
+const (
+	VisitsBadge BadgeKind = "visits" // за общее число посещений
+	StreakBadge BadgeKind = "streak" // за самую длинную серию недель подряд
+)
+
+// BadgeRule представляет правило выдачи награды.
+// Правило без GymID действует во всех залах.
+type BadgeRule struct {
+	ID        string    `json:"id" db:"id"`
+	GymID     *string   `json:"gym_id,omitempty" db:"gym_id"`
+	Code      string    `json:"code" db:"code"`
+	Title     string    `json:"title" db:"title"`
+	Kind      BadgeKind `json:"kind" db:"kind"`
+	Threshold int       `json:"threshold" db:"threshold"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Badge представляет награду, полученную участником
+type Badge struct {
+	UserID    string    `json:"-" db:"user_id"`
+	Code      string    `json:"code" db:"code"`
+	Title     string    `json:"title" db:"title"`
+	AwardedAt time.Time `json:"awarded_at" db:"awarded_at"`
+}
+
+// MemberStats представляет посещения и серии участника в зале.
+// Серия - число недель подряд, в каждую из которых было не меньше заданного числа посещений.
+type MemberStats struct {
+	UserID        string     `json:"user_id" db:"user_id"`
+	GymID         string     `json:"gym_id" db:"gym_id"`
+	TotalVisits   int        `json:"total_visits" db:"total_visits"`
+	CurrentStreak int        `json:"current_streak" db:"current_streak"`
+	LongestStreak int        `json:"longest_streak" db:"longest_streak"`
+	StreakWeek    *time.Time `json:"streak_week,omitempty" db:"streak_week"`
+	Badges        []Badge    `json:"badges" db:"-"`
+}
+
+// RecomputeResult представляет итог пересчета серий и наград зала
+type RecomputeResult struct {
+	Members int `json:"members"`
+	Awarded int `json:"awarded"`
+}
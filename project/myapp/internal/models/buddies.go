@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// BuddyPreferences представляет настройки участника для подбора партнеров по тренировкам.
+// Участник попадает в подсказки других, только если включил подбор и не скрыт.
+type BuddyPreferences struct {
+	OptedIn   bool      `json:"opted_in" db:"opted_in"`
+	Hidden    bool      `json:"hidden" db:"hidden"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// BuddySuggestion представляет участника, который ходит в зал в похожее время.
+// Оценки лежат в диапазоне от 0 до 1.
+type BuddySuggestion struct {
+	UserID       string  `json:"user_id" db:"user_id"`
+	FirstName    string  `json:"first_name" db:"first_name"`
+	LastName     string  `json:"last_name" db:"last_name"`
+	Score        float64 `json:"score" db:"score"`
+	WeekdayScore float64 `json:"weekday_score" db:"weekday_score"`
+	TimeScore    float64 `json:"time_score" db:"time_score"`
+}
+
+// BuddiesResponse представляет ответ с подсказками партнеров
+type BuddiesResponse struct {
+	Weeks   int               `json:"weeks"`
+	Buddies []BuddySuggestion `json:"buddies"`
+}
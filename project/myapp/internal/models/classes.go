@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// LocalTimeLayout - формат местного времени начала занятия в часовом поясе зала
+const LocalTimeLayout = "2006-01-02T15:04"
+
+// Class представляет групповое занятие зала, возможно повторяющееся
+type Class struct {
+	ID              string    `json:"id" db:"id"`
+	GymID           string    `json:"gym_id" db:"gym_id"`
+	Title           string    `json:"title" db:"title"`
+	Description     string    `json:"description,omitempty" db:"description"`
+	StartsAt        string    `json:"starts_at" db:"starts_at"`
+	DurationMinutes int       `json:"duration_minutes" db:"duration_minutes"`
+	RRule           string    `json:"rrule,omitempty" db:"rrule"`
+	Capacity        int       `json:"capacity" db:"capacity"`
+	CreatedBy       string    `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ClassRequest представляет запрос на создание или изменение занятия.
+// StartsAt задается в формате LocalTimeLayout по местному времени зала.
+type ClassRequest struct {
+	Title           string `json:"title"`
+	Description     string `json:"description,omitempty"`
+	StartsAt        string `json:"starts_at"`
+	DurationMinutes int    `json:"duration_minutes"`
+	RRule           string `json:"rrule,omitempty"`
+	Capacity        int    `json:"capacity"`
+}
+
+// BookingStatus определяет состояние записи на занятие
+type BookingStatus string
+
+// Состояния записи на занятие
+const (
+	BookingConfirmed  BookingStatus = "confirmed"  // место подтверждено
+	BookingWaitlisted BookingStatus = "waitlisted" // в листе ожидания
+	BookingCancelled  BookingStatus = "cancelled"  // запись отменена
+)
+
+// Booking представляет запись участника на конкретное занятие серии
+type Booking struct {
+	ID        string        `json:"id" db:"id"`
+	ClassID   string        `json:"class_id" db:"class_id"`
+	UserID    string        `json:"user_id" db:"user_id"`
+	StartsAt  time.Time     `json:"starts_at" db:"starts_at"`
+	Status    BookingStatus `json:"status" db:"status"`
+	Position  int           `json:"waitlist_position,omitempty" db:"-"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+}
+
+// BookingRequest представляет запрос на запись на занятие
+type BookingRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+}
+
+// SessionCounts представляет заполненность конкретного занятия серии
+type SessionCounts struct {
+	ClassID    string         `db:"class_id"`
+	StartsAt   time.Time      `db:"starts_at"`
+	Booked     int            `db:"booked"`
+	Waitlisted int            `db:"waitlisted"`
+	MyStatus   *BookingStatus `db:"my_status"`
+}
+
+// ClassOccurrence представляет конкретное занятие в расписании
+type ClassOccurrence struct {
+	ClassID    string        `json:"class_id"`
+	Title      string        `json:"title"`
+	StartsAt   time.Time     `json:"starts_at"`
+	EndsAt     time.Time     `json:"ends_at"`
+	Capacity   int           `json:"capacity"`
+	Booked     int           `json:"booked"`
+	Waitlisted int           `json:"waitlisted"`
+	MyBooking  BookingStatus `json:"my_booking,omitempty"`
+}
+
+// Schedule представляет расписание занятий зала за период
+type Schedule struct {
+	GymID       string            `json:"gym_id"`
+	Timezone    string            `json:"timezone"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Occurrences []ClassOccurrence `json:"occurrences"`
+}
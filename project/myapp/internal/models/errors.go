@@ -0,0 +1,12 @@
+package models
+
+import "errors"
+
+// Ошибки предметной области, по которым транспортный уровень выбирает код ответа
+var (
+	ErrInvalidArgument    = errors.New("недопустимый аргумент")
+	ErrNotFound           = errors.New("не найдено")
+	ErrForbidden          = errors.New("доступ запрещен")
+	ErrConflict           = errors.New("конфликт")
+	ErrPreconditionFailed = errors.New("запись изменена другим запросом")
+)
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Gym представляет зал организации: адрес, координаты, часовой пояс и часы работы
+type Gym struct {
+	ID             string       `json:"id" db:"id"`
+	OrganizationID string       `json:"organization_id" db:"organization_id"`
+	Name           string       `json:"name" db:"name"`
+	Address        string       `json:"address" db:"address"`
+	Latitude       *float64     `json:"latitude,omitempty" db:"latitude"`
+	Longitude      *float64     `json:"longitude,omitempty" db:"longitude"`
+	Timezone       string       `json:"timezone" db:"timezone"`
+	OpeningHours   OpeningHours `json:"opening_hours" db:"opening_hours"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultTimezone - часовой пояс зала, для которого он не задан
+const DefaultTimezone = "UTC"
+
+// GymRequest представляет запрос на создание или изменение зала.
+// Пустой часовой пояс означает DefaultTimezone. Организацию указывает только
+// администратор платформы, остальные создают залы в своей организации.
+type GymRequest struct {
+	OrganizationID string       `json:"organization_id,omitempty"`
+	Name           string       `json:"name"`
+	Address        string       `json:"address,omitempty"`
+	Latitude       *float64     `json:"latitude,omitempty"`
+	Longitude      *float64     `json:"longitude,omitempty"`
+	Timezone       string       `json:"timezone,omitempty"`
+	OpeningHours   OpeningHours `json:"opening_hours,omitempty"`
+}
+
+// OpeningPeriod представляет часы работы зала в один из дней недели.
+// Время задается в формате "15:04" по местному времени зала; закрытие раньше
+// открытия означает работу после полуночи.
+type OpeningPeriod struct {
+	Weekday string `json:"weekday"`
+	Opens   string `json:"opens"`
+	Closes  string `json:"closes"`
+}
+
+// Weekdays - допустимые значения дня недели в часах работы, начиная с понедельника
+var Weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// OpeningHours представляет недельное расписание работы зала.
+// В базе данных хранится как JSONB.
+type OpeningHours []OpeningPeriod
+
+// Value реализует driver.Valuer
+func (h OpeningHours) Value() (driver.Value, error) {
+	if h == nil {
+		h = OpeningHours{}
+	}
+	return json.Marshal(h)
+}
+
+// Scan реализует sql.Scanner
+func (h *OpeningHours) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, h)
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	case nil:
+		*h = OpeningHours{}
+		return nil
+	default:
+		return fmt.Errorf("неподдерживаемый тип часов работы %T", src)
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// IdempotencyRecord представляет запрос с ключом идемпотентности и сохраненный ответ на него.
+// Пока запрос выполняется, код ответа пуст, а LockedUntil защищает ключ от повторного захвата.
+type IdempotencyRecord struct {
+	ActorID         string          `db:"actor_id"`
+	Key             string          `db:"key"`
+	RequestHash     string          `db:"request_hash"`
+	StatusCode      *int            `db:"status_code"`
+	ContentType     *string         `db:"content_type"`
+	ResponseBody    []byte          `db:"response_body"`
+	ResponseHeaders ResponseHeaders `db:"response_headers"`
+	LockedUntil     time.Time       `db:"locked_until"`
+	CreatedAt       time.Time       `db:"created_at"`
+	ExpiresAt       time.Time       `db:"expires_at"`
+}
+
+// Completed сообщает, сохранен ли ответ на запрос
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != nil
+}
+
+// ResponseHeaders содержит сохраненные заголовки ответа.
+// В базе данных хранится как JSONB.
+type ResponseHeaders map[string]string
+
+// Value реализует driver.Valuer
+func (h ResponseHeaders) Value() (driver.Value, error) {
+	if len(h) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(h)
+}
+
+// Scan реализует sql.Scanner
+func (h *ResponseHeaders) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, h)
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	case nil:
+		*h = nil
+		return nil
+	default:
+		return fmt.Errorf("неподдерживаемый тип заголовков ответа %T", src)
+	}
+}
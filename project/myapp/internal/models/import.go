@@ -0,0 +1,57 @@
+package models
+
+// ImportResult представляет итог импорта одной строки
+type ImportResult string
+
+// Константы итога импорта строки
+const (
+	ImportCreated   ImportResult = "created"   // участник добавлен в зал
+	ImportUpdated   ImportResult = "updated"   // данные пользователя или статус изменены
+	ImportUnchanged ImportResult = "unchanged" // изменений нет
+	ImportFailed    ImportResult = "failed"    // строка отклонена
+)
+
+// ImportMemberRow представляет строку импорта участников
+type ImportMemberRow struct {
+	ID        string         `json:"id"`
+	Email     string         `json:"email"`
+	FirstName string         `json:"first_name"`
+	LastName  string         `json:"last_name"`
+	Status    ActivityStatus `json:"status"`
+}
+
+// ImportRowReport представляет результат импорта строки
+type ImportRowReport struct {
+	Row    int          `json:"row"`
+	UserID string       `json:"user_id,omitempty"`
+	Email  string       `json:"email,omitempty"`
+	Result ImportResult `json:"result"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// ImportReport представляет отчет об импорте участников
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Total     int               `json:"total"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Unchanged int               `json:"unchanged"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowReport `json:"rows"`
+}
+
+// Add учитывает результат строки в отчете
+func (r *ImportReport) Add(row ImportRowReport) {
+	r.Total++
+	switch row.Result {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportUnchanged:
+		r.Unchanged++
+	case ImportFailed:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Invitation представляет приглашение в группу зала
+type Invitation struct {
+	ID            string     `json:"id" db:"id"`
+	GymID         string     `json:"gym_id" db:"gym_id"`
+	Code          string     `json:"code" db:"code"`
+	Link          string     `json:"link,omitempty" db:"-"`
+	Email         *string    `json:"email,omitempty" db:"email"`
+	MaxUses       *int       `json:"max_uses,omitempty" db:"max_uses"`
+	Uses          int        `json:"uses" db:"uses"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy     string     `json:"created_by" db:"created_by"`
+	CreatedByRole *string    `json:"created_by_role,omitempty" db:"created_by_role"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateInvitationRequest представляет запрос на создание приглашения
+type CreateInvitationRequest struct {
+	Email     string     `json:"email,omitempty"`
+	MaxUses   *int       `json:"max_uses,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// GymSettings представляет настройки зала: правило вступления и порог серии посещений
+type GymSettings struct {
+	GymID      string     `json:"gym_id" db:"gym_id"`
+	JoinPolicy JoinPolicy `json:"join_policy" db:"join_policy"`
+	// StreakMinVisits - сколько посещений за неделю нужно, чтобы неделя продлила серию
+	StreakMinVisits int       `json:"streak_min_visits" db:"streak_min_visits"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultStreakMinVisits - число посещений за неделю для серии, если оно не задано
+const DefaultStreakMinVisits = 1
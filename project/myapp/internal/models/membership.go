@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Роли вызывающей стороны, передаваемые в утверждении role токена
+const (
+	RoleUser          = "user"           // обычный пользователь
+	RoleAdmin         = "admin"          // администратор организации
+	RoleService       = "service"        // внутренний сервис
+	RolePlatformAdmin = "platform_admin" // администратор платформы, работает со всеми организациями
+)
+
+// Actor представляет того, кто выполняет действие
+type Actor struct {
+	UserID string
+	Role   string
+}
+
+// StatusChange представляет изменение статуса участника.
+// Если ExpectedVersions не пуст, изменение применяется, только когда текущая
+// версия записи участника совпадает с одной из них.
+type StatusChange struct {
+	Status           ActivityStatus
+	Reason           string
+	ChangedBy        string
+	ChangedByRole    string // Роль изменившего, в том числе когда участник меняет статус сам
+	ExpectedVersions []int64
+}
+
+// MemberStatus представляет статус участника вместе с версией записи,
+// которая увеличивается при каждом изменении
+type MemberStatus struct {
+	Status  ActivityStatus `json:"status" db:"status"`
+	Version int64          `json:"-" db:"version"`
+}
+
+// StatusTransition представляет допустимый переход статуса участника
+type StatusTransition struct {
+	Status         ActivityStatus `json:"status"`
+	RequiresReason bool           `json:"requires_reason"`
+}
+
+// AllowedTransitionsResponse представляет ответ со списком допустимых переходов
+type AllowedTransitionsResponse struct {
+	Status      ActivityStatus     `json:"status"`
+	Transitions []StatusTransition `json:"transitions"`
+}
+
+// StatusHistoryEntry представляет запись истории статусов участника
+type StatusHistoryEntry struct {
+	ID            int64           `json:"id" db:"id"`
+	UserID        string          `json:"user_id" db:"user_id"`
+	GymID         string          `json:"gym_id" db:"gym_id"`
+	FromStatus    *ActivityStatus `json:"from_status,omitempty" db:"from_status"`
+	ToStatus      ActivityStatus  `json:"to_status" db:"to_status"`
+	Reason        *string         `json:"reason,omitempty" db:"reason"`
+	ChangedBy     *string         `json:"changed_by,omitempty" db:"changed_by"`
+	ChangedByRole *string         `json:"changed_by_role,omitempty" db:"changed_by_role"`
+	ChangedAt     time.Time       `json:"changed_at" db:"changed_at"`
+}
@@ -9,8 +9,36 @@ type ActivityStatus string
 
 // Константы статуса активности
 const (
-	ActiveStatus   ActivityStatus = "active"   // активен - ходит в зал
-	InactiveStatus ActivityStatus = "inactive" // неактивен - не ходит в зал
+	ActiveStatus    ActivityStatus = "active"    // активен - ходит в зал
+	InactiveStatus  ActivityStatus = "inactive"  // неактивен - не ходит в зал
+	PendingStatus   ActivityStatus = "pending"   // ожидает одобрения заявки на вступление
+	RejectedStatus  ActivityStatus = "rejected"  // заявка на вступление отклонена
+	SuspendedStatus ActivityStatus = "suspended" // участие временно приостановлено администратором
+	LeftStatus      ActivityStatus = "left"      // покинул группу зала
+	BannedStatus    ActivityStatus = "banned"    // заблокирован администратором
+)
+
+// MemberStatuses - статусы, с которыми пользователь считается участником группы
+var MemberStatuses = []ActivityStatus{ActiveStatus, InactiveStatus, SuspendedStatus}
+
+// IsMember сообщает, является ли пользователь с этим статусом участником группы
+func (s ActivityStatus) IsMember() bool {
+	for _, status := range MemberStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// JoinPolicy представляет правило вступления в группу зала
+type JoinPolicy string
+
+// Константы правила вступления
+const (
+	OpenPolicy       JoinPolicy = "open"        // вступить может любой пользователь
+	ApprovalPolicy   JoinPolicy = "approval"    // заявку должен одобрить администратор
+	InviteOnlyPolicy JoinPolicy = "invite_only" // вступить можно только по приглашению
 )
 
 // User представляет пользователя в сервисе групп
@@ -22,6 +50,14 @@ type User struct {
 	Status    ActivityStatus `json:"status" db:"status"`
 	CreatedAt time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
+	Badges    []Badge        `json:"badges,omitempty" db:"-"`
+}
+
+// ListVersion описывает состояние списка для условных запросов: хэш всего, что
+// попадает в ответ, и время последнего изменения
+type ListVersion struct {
+	Hash         string    `json:"hash" db:"hash"`
+	LastModified time.Time `json:"last_modified" db:"last_modified"`
 }
 
 // GroupMember представляет членство пользователя в группе зала
@@ -45,9 +81,25 @@ type Group struct {
 // UpdateStatusRequest представляет запрос на обновление статуса пользователя
 type UpdateStatusRequest struct {
 	Status ActivityStatus `json:"status"`
+	Reason string         `json:"reason,omitempty"`
 }
 
 // GroupMembersResponse представляет ответ со списком участников группы
 type GroupMembersResponse struct {
 	Members []User `json:"members"`
 }
+
+// JoinRequest представляет заявку на вступление в группу зала
+type JoinRequest struct {
+	UserID      string         `json:"user_id" db:"user_id"`
+	Email       string         `json:"email" db:"email"`
+	FirstName   string         `json:"first_name" db:"first_name"`
+	LastName    string         `json:"last_name" db:"last_name"`
+	Status      ActivityStatus `json:"status" db:"status"`
+	RequestedAt time.Time      `json:"requested_at" db:"joined_at"`
+}
+
+// ReviewRequest представляет решение администратора по заявке
+type ReviewRequest struct {
+	Reason string `json:"reason"`
+}
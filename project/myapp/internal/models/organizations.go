@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Organization представляет сеть фитнес-клубов, которой принадлежат залы.
+// Данные разных организаций не пересекаются.
+type Organization struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OrganizationRequest представляет запрос на создание организации
+type OrganizationRequest struct {
+	Name string `json:"name"`
+}
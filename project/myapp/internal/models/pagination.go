@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// PageKey - ключ сортировки записи в списке с ключевой пагинацией:
+// время и ID, который различает записи с одинаковым временем
+type PageKey struct {
+	Time time.Time
+	ID   string
+}
+
+// PageQuery задает страницу списка: записи после ключа After в порядке списка
+// или, если Backward, записи перед ним. Без After запрашивается первая страница.
+type PageQuery struct {
+	After    *PageKey
+	Backward bool
+	Limit    int
+}
+
+// PageLinks содержит ссылки на соседние страницы списка
+type PageLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// MemberListEntry представляет участника зала в постраничном списке
+type MemberListEntry struct {
+	User
+	JoinedAt     time.Time `json:"joined_at" db:"joined_at"`
+	MembershipID string    `json:"-" db:"membership_id"`
+}
+
+// MembersPage представляет страницу списка участников зала в порядке вступления
+type MembersPage struct {
+	Members    []MemberListEntry `json:"members"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	PrevCursor string            `json:"prev_cursor,omitempty"`
+	Links      PageLinks         `json:"links"`
+}
+
+// StatusHistoryPage представляет страницу истории статусов участника, от новых записей к старым
+type StatusHistoryPage struct {
+	Entries    []StatusHistoryEntry `json:"entries"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+	PrevCursor string               `json:"prev_cursor,omitempty"`
+	Links      PageLinks            `json:"links"`
+}
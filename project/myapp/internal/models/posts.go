@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Post представляет публикацию в ленте группы зала
+type Post struct {
+	ID              string    `json:"id" db:"id"`
+	GymID           string    `json:"gym_id" db:"gym_id"`
+	AuthorID        string    `json:"author_id" db:"author_id"`
+	AuthorFirstName string    `json:"author_first_name" db:"author_first_name"`
+	AuthorLastName  string    `json:"author_last_name" db:"author_last_name"`
+	Text            string    `json:"text" db:"text"`
+	Pinned          bool      `json:"pinned" db:"pinned"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PostRequest представляет запрос на создание или изменение публикации.
+// Поле pinned учитывается только для администраторов.
+type PostRequest struct {
+	Text   *string `json:"text,omitempty"`
+	Pinned *bool   `json:"pinned,omitempty"`
+}
+
+// PostsPage представляет страницу ленты группы.
+// Закрепленные публикации возвращаются отдельно на первой странице.
+type PostsPage struct {
+	Pinned     []Post    `json:"pinned,omitempty"`
+	Posts      []Post    `json:"posts"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
+	Links      PageLinks `json:"links"`
+	Unread     int       `json:"unread"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DataExport представляет выгрузку персональных данных пользователя
+type DataExport struct {
+	ExportedAt            time.Time              `json:"exported_at"`
+	User                  User                   `json:"user"`
+	Memberships           []GroupMember          `json:"memberships"`
+	StatusHistory         []StatusHistoryEntry   `json:"status_history"`
+	Visits                []Visit                `json:"visits"`
+	Stats                 []MemberStats          `json:"stats"`
+	Posts                 []Post                 `json:"posts"`
+	PostReads             []PostRead             `json:"post_reads"`
+	Bookings              []Booking              `json:"bookings"`
+	BuddyPreferences      []GymBuddyPreferences  `json:"buddy_preferences"`
+	InvitationRedemptions []InvitationRedemption `json:"invitation_redemptions"`
+}
+
+// PostRead представляет момент, до которого пользователь прочитал ленту зала
+type PostRead struct {
+	GymID      string    `json:"gym_id" db:"gym_id"`
+	LastReadAt time.Time `json:"last_read_at" db:"last_read_at"`
+}
+
+// GymBuddyPreferences представляет настройки подбора партнеров пользователя в зале
+type GymBuddyPreferences struct {
+	GymID string `json:"gym_id" db:"gym_id"`
+	BuddyPreferences
+}
+
+// InvitationRedemption представляет вступление пользователя в зал по приглашению
+type InvitationRedemption struct {
+	InvitationID string    `json:"invitation_id" db:"invitation_id"`
+	GymID        string    `json:"gym_id" db:"gym_id"`
+	RedeemedAt   time.Time `json:"redeemed_at" db:"redeemed_at"`
+}
+
+// EraseRequest представляет запрос на удаление персональных данных пользователя
+type EraseRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// Erasure представляет запись журнала удаления персональных данных.
+// GymIDs - залы, из которых пользователь вышел при удалении. Если OrganizationID
+// задан, удалены только данные в залах этой организации, а учетная запись сохранена.
+type Erasure struct {
+	ID             int64          `json:"id" db:"id"`
+	UserID         string         `json:"user_id" db:"user_id"`
+	ErasedBy       string         `json:"erased_by" db:"erased_by"`
+	ActorRole      string         `json:"actor_role" db:"actor_role"`
+	OrganizationID *string        `json:"organization_id,omitempty" db:"organization_id"`
+	Reason         *string        `json:"reason,omitempty" db:"reason"`
+	GymIDs         pq.StringArray `json:"gym_ids" db:"gym_ids"`
+	ErasedAt       time.Time      `json:"erased_at" db:"erased_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Visit представляет посещение зала участником
+type Visit struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	GymID     string    `json:"gym_id" db:"gym_id"`
+	VisitedAt time.Time `json:"visited_at" db:"visited_at"`
+	NewBadges []Badge   `json:"new_badges,omitempty" db:"-"`
+}
+
+// CheckInRequest представляет запрос на отметку посещения.
+// UserID и VisitedAt могут задавать только администраторы и сервисы.
+type CheckInRequest struct {
+	UserID    string     `json:"user_id,omitempty"`
+	VisitedAt *time.Time `json:"visited_at,omitempty"`
+}
+
+// LeaderboardPeriod определяет период, за который считается рейтинг
+type LeaderboardPeriod string
+
+// Периоды рейтинга
+const (
+	WeekPeriod  LeaderboardPeriod = "week"  // текущая неделя
+	MonthPeriod LeaderboardPeriod = "month" // текущий месяц
+	AllPeriod   LeaderboardPeriod = "all"   // все время
+)
+
+// LeaderboardEntry представляет место участника в рейтинге
+type LeaderboardEntry struct {
+	Rank      int    `json:"rank" db:"rank"`
+	UserID    string `json:"user_id" db:"user_id"`
+	FirstName string `json:"first_name" db:"first_name"`
+	LastName  string `json:"last_name" db:"last_name"`
+	Visits    int    `json:"visits" db:"visits"`
+	Streak    int    `json:"streak" db:"streak"`
+}
+
+// Leaderboard представляет рейтинг участников группы по посещениям
+type Leaderboard struct {
+	GymID   string             `json:"gym_id"`
+	Period  LeaderboardPeriod  `json:"period"`
+	Since   *time.Time         `json:"since,omitempty"`
+	Entries []LeaderboardEntry `json:"entries"`
+	Me      *LeaderboardEntry  `json:"me,omitempty"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DeliveryStatus представляет состояние доставки вебхука
+type DeliveryStatus string
+
+// Константы состояния доставки вебхука
+const (
+	DeliveryPending   DeliveryStatus = "pending"   // ожидает отправки или повтора
+	DeliverySucceeded DeliveryStatus = "succeeded" // доставлена
+	DeliveryFailed    DeliveryStatus = "failed"    // попытки исчерпаны
+)
+
+// Webhook представляет подписку зала-партнера на события
+type Webhook struct {
+	ID                  string         `json:"id" db:"id"`
+	GymID               string         `json:"gym_id" db:"gym_id"`
+	URL                 string         `json:"url" db:"url"`
+	Secret              string         `json:"secret,omitempty" db:"secret"`
+	EventTypes          pq.StringArray `json:"event_types" db:"event_types"`
+	Enabled             bool           `json:"enabled" db:"enabled"`
+	ConsecutiveFailures int            `json:"consecutive_failures" db:"consecutive_failures"`
+	DisabledReason      *string        `json:"disabled_reason,omitempty" db:"disabled_reason"`
+	CreatedAt           time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookDelivery представляет запись журнала доставки вебхука
+type WebhookDelivery struct {
+	ID             string          `json:"id" db:"id"`
+	WebhookID      string          `json:"webhook_id" db:"webhook_id"`
+	EventID        int64           `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         DeliveryStatus  `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// WebhookRequest представляет запрос на создание или изменение вебхука
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
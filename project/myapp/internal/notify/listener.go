@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"myapp/internal/events"
+)
+
+// Listener слушает канал LISTEN/NOTIFY и рассылает изменения подписчикам внутри процесса.
+// Соединение восстанавливается автоматически.
+type Listener struct {
+	dsn     string
+	channel string
+
+	mu          sync.RWMutex
+	nextID      int
+	handlers    map[int]func(events.Event)
+	onReconnect map[int]func()
+}
+
+// NewListener создает нового слушателя канала channel
+func NewListener(dsn, channel string) *Listener {
+	return &Listener{
+		dsn:         dsn,
+		channel:     channel,
+		handlers:    make(map[int]func(events.Event)),
+		onReconnect: make(map[int]func()),
+	}
+}
+
+// Subscribe регистрирует обработчик изменений и возвращает функцию отписки.
+// Обработчики вызываются последовательно и не должны блокироваться.
+func (l *Listener) Subscribe(handler func(events.Event)) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := l.nextID
+	l.nextID++
+	l.handlers[id] = handler
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.handlers, id)
+	}
+}
+
+// OnReconnect регистрирует обработчик переподключения. Пока соединения не было,
+// уведомления могли быть потеряны, поэтому подписчикам стоит сбросить свое состояние.
+func (l *Listener) OnReconnect(handler func()) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := l.nextID
+	l.nextID++
+	l.onReconnect[id] = handler
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.onReconnect, id)
+	}
+}
+
+// Run слушает канал до отмены контекста
+func (l *Listener) Run(ctx context.Context) error {
+	listener := pq.NewListener(l.dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Printf("Не удалось подключиться к каналу %s: %v", l.channel, err)
+		case pq.ListenerEventDisconnected:
+			log.Printf("Потеряно соединение с каналом %s: %v", l.channel, err)
+		case pq.ListenerEventReconnected:
+			log.Printf("Восстановлено соединение с каналом %s", l.channel)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(l.channel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// nil приходит после переподключения
+			if n == nil {
+				l.reset()
+				continue
+			}
+			l.dispatch(n.Extra)
+		case <-time.After(90 * time.Second):
+			// Проверяем, что соединение живо
+			go listener.Ping()
+		}
+	}
+}
+
+// dispatch разбирает уведомление и передает его подписчикам
+func (l *Listener) dispatch(payload string) {
+	var event events.Event
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		log.Printf("Недопустимое уведомление в канале %s: %v", l.channel, err)
+		return
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, handler := range l.handlers {
+		handler(event)
+	}
+}
+
+// reset сообщает подписчикам о переподключении
+func (l *Listener) reset() {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, handler := range l.onReconnect {
+		handler()
+	}
+}
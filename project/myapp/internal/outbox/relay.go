@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"myapp/internal/events"
+)
+
+// Store определяет интерфейс хранилища outbox
+type Store interface {
+	// ClaimPending захватывает до limit неопубликованных событий в порядке записи
+	// на время lease. События участника, предыдущее событие которого захвачено
+	// другой репликой, не захватываются, чтобы сохранить порядок.
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]events.Event, error)
+	// CompleteBatch отмечает опубликованные события и ошибки публикации
+	// и снимает захват с остальных событий пачки
+	CompleteBatch(ctx context.Context, batch []events.Event, published []int64, failed map[int64]error) error
+	// DeletePublished удаляет события, опубликованные раньше указанного момента
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+// completeTimeout ограничивает сохранение результата публикации пачки
+const completeTimeout = 10 * time.Second
+
+// Config содержит настройки ретранслятора
+type Config struct {
+	BatchSize       int           // Размер пачки событий
+	PollInterval    time.Duration // Интервал опроса outbox
+	Lease           time.Duration // Время, на которое реплика захватывает пачку для публикации
+	Retention       time.Duration // Время хранения опубликованных событий
+	CleanupInterval time.Duration // Интервал очистки опубликованных событий
+}
+
+// Relay пересылает события из outbox издателю с доставкой "хотя бы один раз"
+type Relay struct {
+	store     Store
+	publisher events.Publisher
+	cfg       Config
+}
+
+// NewRelay создает новый ретранслятор outbox
+func NewRelay(store Store, publisher events.Publisher, cfg Config) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		cfg:       cfg,
+	}
+}
+
+// Run опрашивает outbox до отмены контекста
+func (r *Relay) Run(ctx context.Context) {
+	poll := time.NewTicker(r.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(r.cfg.CleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			r.drain(ctx)
+		case <-cleanup.C:
+			deleted, err := r.store.DeletePublished(ctx, time.Now().Add(-r.cfg.Retention))
+			if err != nil {
+				log.Printf("Ошибка очистки outbox: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Удалено опубликованных событий outbox: %d", deleted)
+			}
+		}
+	}
+}
+
+// drain обрабатывает пачки, пока в outbox есть события. Пачка захватывается
+// и отмечается в коротких транзакциях, а публикуется вне транзакции.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		batch, err := r.store.ClaimPending(ctx, r.cfg.BatchSize, r.cfg.Lease)
+		if err != nil {
+			log.Printf("Ошибка захвата событий outbox: %v", err)
+			return
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		published, failed := r.publish(ctx, batch)
+
+		// Результат сохраняется и при остановке: иначе события опубликуются повторно
+		completeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), completeTimeout)
+		err = r.store.CompleteBatch(completeCtx, batch, published, failed)
+		cancel()
+		if err != nil {
+			log.Printf("Ошибка сохранения результата публикации outbox: %v", err)
+			return
+		}
+
+		// Неудачные события повторяются на следующем опросе
+		if len(batch) < r.cfg.BatchSize || len(failed) > 0 {
+			return
+		}
+	}
+}
+
+// publish публикует события по порядку. После первой ошибки по участнику
+// его последующие события пачки пропускаются, чтобы сохранить порядок.
+func (r *Relay) publish(ctx context.Context, batch []events.Event) ([]int64, map[int64]error) {
+	var published []int64
+	failed := make(map[int64]error)
+	blocked := make(map[string]bool)
+
+	for _, event := range batch {
+		if blocked[event.AggregateKey] {
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			failed[event.ID] = err
+			blocked[event.AggregateKey] = true
+			continue
+		}
+
+		published = append(published, event.ID)
+	}
+
+	return published, failed
+}
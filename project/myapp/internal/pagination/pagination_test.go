@@ -0,0 +1,75 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"myapp/internal/models"
+)
+
+func TestRoundTrip(t *testing.T) {
+	signer := NewSigner("secret")
+	key := models.PageKey{Time: time.Date(2024, 1, 2, 3, 4, 5, 678901234, time.UTC), ID: "post-1"}
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"вперед", Cursor{Scope: "posts:gym-1", Key: key}},
+		{"назад", Cursor{Scope: "posts:gym-1", Key: key, Backward: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signer.Decode(signer.Encode(tt.cursor), tt.cursor.Scope)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got.Scope != tt.cursor.Scope || got.Backward != tt.cursor.Backward ||
+				got.Key.ID != key.ID || !got.Key.Time.Equal(key.Time) {
+				t.Errorf("ожидалось %+v, получено %+v", tt.cursor, got)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	signer := NewSigner("secret")
+	cursor := Cursor{Scope: "posts:gym-1", Key: models.PageKey{Time: time.Now(), ID: "post-1"}}
+	token := signer.Encode(cursor)
+	encoded, signature, _ := strings.Cut(token, ".")
+
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"posts:gym-1","t":0,"i":"post-2"}`))
+	empty := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"posts:gym-1","t":0,"i":""}`))
+	sign := func(encoded string) string {
+		return encoded + "." + base64.RawURLEncoding.EncodeToString(signer.sign(encoded))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		scope string
+	}{
+		{"другой список", token, "posts:gym-2"},
+		{"другой ключ подписи", NewSigner("other").Encode(cursor), cursor.Scope},
+		{"измененные данные", forged + "." + signature, cursor.Scope},
+		{"измененная подпись", encoded + "." + base64.RawURLEncoding.EncodeToString([]byte("signature")), cursor.Scope},
+		{"без подписи", encoded, cursor.Scope},
+		{"пустая строка", "", cursor.Scope},
+		{"подпись не в base64", encoded + ".!!!", cursor.Scope},
+		{"данные не в JSON", sign(base64.RawURLEncoding.EncodeToString([]byte("cursor"))), cursor.Scope},
+		{"пустой ID", sign(empty), cursor.Scope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := signer.Decode(tt.token, tt.scope)
+			if !errors.Is(err, models.ErrInvalidArgument) {
+				t.Errorf("ожидалась ошибка ErrInvalidArgument, получено %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"myapp/internal/events"
+	"myapp/internal/models"
+	"myapp/internal/service"
+	"myapp/internal/tenant"
+)
+
+// Backend определяет хранилище закэшированных значений
+type Backend interface {
+	// Get возвращает значение и false, если ключа нет или его TTL истек
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Flush удаляет все значения кэша
+	Flush(ctx context.Context) error
+}
+
+// Stats содержит счетчики обращений к кэшу
+type Stats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Errors        uint64 `json:"errors"`
+	Invalidations uint64 `json:"invalidations"`
+}
+
+// Repository - кэширующий декоратор service.Repository для списков участников.
+// Значения читаются через кэш, а записи сбрасывают затронутые ключи.
+// Одинаковые одновременные промахи объединяются в один запрос к базе.
+type Repository struct {
+	service.Repository
+
+	backend Backend
+	ttl     time.Duration
+	group   singleflight.Group
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	errors        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// NewRepository оборачивает репозиторий кэшем с временем жизни записей ttl
+func NewRepository(repo service.Repository, backend Backend, ttl time.Duration) *Repository {
+	return &Repository{
+		Repository: repo,
+		backend:    backend,
+		ttl:        ttl,
+	}
+}
+
+// loadTimeout ограничивает загрузку значения при промахе, общую для всех ожидающих
+const loadTimeout = 10 * time.Second
+
+// Ключи кэша
+func membersKey(gymID string) string        { return "members:" + gymID }
+func statusKey(userID, gymID string) string { return "status:" + gymID + ":" + userID }
+func gymKey(gymID string) string            { return "gym:" + gymID }
+func membersVersionKey(gymID string) string { return "membersversion:" + gymID }
+
+// userGroupKey - ключ группы пользователя: у пользователя может быть своя группа
+// в каждой организации, а вызывающий с доступом ко всем организациям видит любую из них
+func userGroupKey(orgID, userID string) string { return "usergroup:" + orgID + ":" + userID }
+
+// allOrganizations - организация в ключах кэша для контекста с доступом ко всем организациям
+const allOrganizations = "*"
+
+// scope возвращает организацию вызывающего для ключей кэша
+func scope(ctx context.Context) string {
+	if tenant.AllOrganizations(ctx) {
+		return allOrganizations
+	}
+	return tenant.OrganizationID(ctx)
+}
+
+// GetGroupMembers получает участников зала через кэш
+func (r *Repository) GetGroupMembers(ctx context.Context, gymID string) ([]models.User, error) {
+	if err := r.checkTenant(ctx, gymID); err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	err := r.readThrough(ctx, membersKey(gymID), &users, func(ctx context.Context) (interface{}, error) {
+		return r.Repository.GetGroupMembers(ctx, gymID)
+	})
+	return users, err
+}
+
+// GetUserGroup получает группу пользователя через кэш. Группа и ее участники
+// кэшируются отдельно, чтобы изменение состава зала сбрасывало один ключ.
+// Группа кэшируется отдельно для каждой организации.
+func (r *Repository) GetUserGroup(ctx context.Context, userID string) (models.Group, []models.User, error) {
+	group, err := r.userGroup(ctx, userID)
+	if err != nil {
+		return models.Group{}, nil, err
+	}
+
+	users, err := r.GetGroupMembers(ctx, group.GymID)
+	if err != nil {
+		return models.Group{}, nil, err
+	}
+
+	return group, users, nil
+}
+
+// GetGroupMembersVersion получает версию списка участников зала через кэш.
+// Версия сбрасывается вместе со списком, поэтому совпадает с закэшированным списком.
+func (r *Repository) GetGroupMembersVersion(ctx context.Context, gymID string) (models.ListVersion, error) {
+	if err := r.checkTenant(ctx, gymID); err != nil {
+		return models.ListVersion{}, err
+	}
+
+	var version models.ListVersion
+	err := r.readThrough(ctx, membersVersionKey(gymID), &version, func(ctx context.Context) (interface{}, error) {
+		return r.Repository.GetGroupMembersVersion(ctx, gymID)
+	})
+	return version, err
+}
+
+// GetUserGroupVersion получает версию группы пользователя через кэш
+func (r *Repository) GetUserGroupVersion(ctx context.Context, userID string) (models.ListVersion, error) {
+	group, err := r.userGroup(ctx, userID)
+	if err != nil {
+		return models.ListVersion{}, err
+	}
+
+	return r.GetGroupMembersVersion(ctx, group.GymID)
+}
+
+// userGroup получает группу пользователя в организации из контекста через кэш
+func (r *Repository) userGroup(ctx context.Context, userID string) (models.Group, error) {
+	var group models.Group
+	err := r.readThrough(ctx, userGroupKey(scope(ctx), userID), &group, func(ctx context.Context) (interface{}, error) {
+		group, users, err := r.Repository.GetUserGroup(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		// Участники уже получены - сохраняем их, чтобы не запрашивать повторно
+		r.store(ctx, membersKey(group.GymID), users)
+		return group, nil
+	})
+	return group, err
+}
+
+// GetUserStatus получает статус пользователя в зале через кэш
+func (r *Repository) GetUserStatus(ctx context.Context, userID, gymID string) (models.ActivityStatus, error) {
+	if err := r.checkTenant(ctx, gymID); err != nil {
+		return "", err
+	}
+
+	var status models.ActivityStatus
+	err := r.readThrough(ctx, statusKey(userID, gymID), &status, func(ctx context.Context) (interface{}, error) {
+		return r.Repository.GetUserStatus(ctx, userID, gymID)
+	})
+	return status, err
+}
+
+// UpdateUserStatus обновляет статус и сбрасывает затронутые ключи
+func (r *Repository) UpdateUserStatus(ctx context.Context, userID, gymID string, change models.StatusChange, check func(models.ActivityStatus) error) (models.MemberStatus, error) {
+	status, err := r.Repository.UpdateUserStatus(ctx, userID, gymID, change, check)
+	r.InvalidateMember(ctx, userID, gymID)
+	return status, err
+}
+
+// AddUserToGym добавляет пользователя в зал и сбрасывает затронутые ключи
+func (r *Repository) AddUserToGym(ctx context.Context, actor models.Actor, userID, gymID string, status models.ActivityStatus) error {
+	err := r.Repository.AddUserToGym(ctx, actor, userID, gymID, status)
+	r.InvalidateMember(ctx, userID, gymID)
+	return err
+}
+
+// checkTenant проверяет, что зал принадлежит организации из контекста.
+// Зал кэшируется без учета организации, поэтому ответ из кэша одинаков для всех
+// вызывающих, а решение принимается по организации зала. Контекст без организации
+// и без доступа ко всем организациям не видит ни одного зала.
+func (r *Repository) checkTenant(ctx context.Context, gymID string) error {
+	orgID := scope(ctx)
+	if orgID == allOrganizations {
+		return nil
+	}
+	if orgID == "" {
+		return fmt.Errorf("%w: зал не найден", models.ErrNotFound)
+	}
+
+	gym, err := r.gym(ctx, gymID)
+	if err != nil {
+		return err
+	}
+	if gym.OrganizationID != orgID {
+		return fmt.Errorf("%w: зал не найден", models.ErrNotFound)
+	}
+
+	return nil
+}
+
+// gym получает зал через кэш без учета организации
+func (r *Repository) gym(ctx context.Context, gymID string) (models.Gym, error) {
+	var gym models.Gym
+	err := r.readThrough(ctx, gymKey(gymID), &gym, func(ctx context.Context) (interface{}, error) {
+		return r.Repository.GetGym(tenant.Unscoped(ctx), gymID)
+	})
+	return gym, err
+}
+
+// userGroupKeys возвращает ключи групп пользователей, которые затрагивает изменение
+// их участия в зале: для вызывающих со всеми организациями и для организации зала
+func (r *Repository) userGroupKeys(ctx context.Context, gymID string, userIDs ...string) []string {
+	gym, err := r.gym(ctx, gymID)
+	if err != nil {
+		// Без организации ключ не сбросить, запись устареет по TTL
+		r.errors.Add(1)
+		log.Printf("Ошибка получения зала %s для сброса кэша: %v", gymID, err)
+	}
+
+	keys := make([]string, 0, 2*len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, userGroupKey(allOrganizations, userID))
+		if gym.OrganizationID != "" {
+			keys = append(keys, userGroupKey(gym.OrganizationID, userID))
+		}
+	}
+	return keys
+}
+
+// InvalidateMember сбрасывает ключи, зависящие от участника зала
+func (r *Repository) InvalidateMember(ctx context.Context, userID, gymID string) {
+	r.invalidations.Add(1)
+	keys := append(r.userGroupKeys(ctx, gymID, userID), membersKey(gymID), membersVersionKey(gymID), statusKey(userID, gymID))
+	if err := r.backend.Delete(ctx, keys...); err != nil {
+		r.errors.Add(1)
+		log.Printf("Ошибка сброса кэша участника %s в зале %s: %v", userID, gymID, err)
+	}
+}
+
+// HandleChange сбрасывает кэш по уведомлению об изменении с другой реплики
+func (r *Repository) HandleChange(event events.Event) {
+	payload, err := event.DecodeMember()
+	if err != nil {
+		return
+	}
+	r.InvalidateMember(context.Background(), payload.UserID, payload.GymID)
+}
+
+// Flush сбрасывает весь кэш, например после потери уведомлений
+func (r *Repository) Flush() {
+	r.invalidations.Add(1)
+	if err := r.backend.Flush(context.Background()); err != nil {
+		r.errors.Add(1)
+		log.Printf("Ошибка очистки кэша: %v", err)
+	}
+}
+
+// Stats возвращает счетчики обращений к кэшу
+func (r *Repository) Stats() Stats {
+	return Stats{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Errors:        r.errors.Load(),
+		Invalidations: r.invalidations.Load(),
+	}
+}
+
+// readThrough читает значение из кэша, а при промахе загружает его через load.
+// Ошибки кэша не прерывают запрос: значение загружается из базы.
+// Загрузка общая для одновременных промахов, поэтому она выполняется с контекстом
+// без отмены первого вызывающего и собственным таймаутом, а каждый вызывающий
+// перестает ждать ее при отмене своего контекста.
+func (r *Repository) readThrough(ctx context.Context, key string, dest interface{}, load func(ctx context.Context) (interface{}, error)) error {
+	data, ok, err := r.backend.Get(ctx, key)
+	if err != nil {
+		r.errors.Add(1)
+	}
+	if ok {
+		if err := json.Unmarshal(data, dest); err == nil {
+			r.hits.Add(1)
+			return nil
+		}
+		r.errors.Add(1)
+	}
+	r.misses.Add(1)
+
+	result := r.group.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		value, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		return r.store(loadCtx, key, value), nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return res.Err
+		}
+		return json.Unmarshal(res.Val.([]byte), dest)
+	}
+}
+
+// store сохраняет значение в кэше и возвращает его сериализованное представление
+func (r *Repository) store(ctx context.Context, key string, value interface{}) []byte {
+	data, err := json.Marshal(value)
+	if err != nil {
+		r.errors.Add(1)
+		return nil
+	}
+
+	if err := r.backend.Set(ctx, key, data, r.ttl); err != nil {
+		r.errors.Add(1)
+	}
+	return data
+}
+
+// ImportMembers импортирует участников и сбрасывает список участников зала
+func (r *Repository) ImportMembers(ctx context.Context, actor models.Actor, gymID string, rows []models.ImportMemberRow, dryRun bool, check func(from, to models.ActivityStatus) error) ([]models.ImportRowReport, error) {
+	reports, err := r.Repository.ImportMembers(ctx, actor, gymID, rows, dryRun, check)
+	if err == nil && !dryRun {
+		keys := []string{membersKey(gymID), membersVersionKey(gymID)}
+		userIDs := make([]string, len(rows))
+		for i, row := range rows {
+			userIDs[i] = row.ID
+			keys = append(keys, statusKey(row.ID, gymID))
+		}
+		keys = append(keys, r.userGroupKeys(ctx, gymID, userIDs...)...)
+		r.invalidations.Add(1)
+		if err := r.backend.Delete(ctx, keys...); err != nil {
+			r.errors.Add(1)
+			log.Printf("Ошибка сброса кэша зала %s: %v", gymID, err)
+		}
+	}
+	return reports, err
+}
+
+// RedeemInvitation погашает приглашение и сбрасывает затронутые ключи
+func (r *Repository) RedeemInvitation(ctx context.Context, actor models.Actor, code string, check func(models.Invitation, string) error) (models.Invitation, error) {
+	invitation, err := r.Repository.RedeemInvitation(ctx, actor, code, check)
+	if err == nil {
+		r.InvalidateMember(ctx, actor.UserID, invitation.GymID)
+	}
+	return invitation, err
+}
+
+// UpdateGym изменяет зал и сбрасывает кэш: название группы хранится в ключах пользователей
+func (r *Repository) UpdateGym(ctx context.Context, gym models.Gym) (models.Gym, error) {
+	updated, err := r.Repository.UpdateGym(ctx, gym)
+	if err == nil {
+		r.Flush()
+	}
+	return updated, err
+}
+
+// DeleteGym удаляет зал и сбрасывает его ключи, чтобы проверка организации
+// не находила удаленный зал в кэше
+func (r *Repository) DeleteGym(ctx context.Context, gymID string) error {
+	err := r.Repository.DeleteGym(ctx, gymID)
+	if err == nil {
+		r.invalidations.Add(1)
+		if err := r.backend.Delete(ctx, gymKey(gymID), membersKey(gymID), membersVersionKey(gymID)); err != nil {
+			r.errors.Add(1)
+			log.Printf("Ошибка сброса кэша зала %s: %v", gymID, err)
+		}
+	}
+	return err
+}
+
+// EraseUser удаляет данные пользователя и сбрасывает ключи залов, из которых он вышел
+func (r *Repository) EraseUser(ctx context.Context, erasure models.Erasure) (models.Erasure, error) {
+	recorded, err := r.Repository.EraseUser(ctx, erasure)
+	if err == nil {
+		for _, gymID := range recorded.GymIDs {
+			r.InvalidateMember(ctx, recorded.UserID, gymID)
+		}
+	}
+	return recorded, err
+}
+
+// CreateVisit сохраняет посещение и сбрасывает версию списка участников,
+// если участник получил новые награды
+func (r *Repository) CreateVisit(ctx context.Context, visit models.Visit, streakMinVisits int) (models.Visit, error) {
+	created, err := r.Repository.CreateVisit(ctx, visit, streakMinVisits)
+	if err == nil && len(created.NewBadges) > 0 {
+		r.invalidateMembersVersion(ctx, visit.GymID)
+	}
+	return created, err
+}
+
+// RefreshMemberStats пересчитывает статистику участника и сбрасывает версию списка
+// участников, если он получил новые награды
+func (r *Repository) RefreshMemberStats(ctx context.Context, gymID, userID string, minVisits int) (int, []models.Badge, error) {
+	members, awarded, err := r.Repository.RefreshMemberStats(ctx, gymID, userID, minVisits)
+	if err == nil && len(awarded) > 0 {
+		r.invalidateMembersVersion(ctx, gymID)
+	}
+	return members, awarded, err
+}
+
+// DeleteBadgeRule удаляет правило вместе с выданными по нему наградами
+// и сбрасывает версию списка участников
+func (r *Repository) DeleteBadgeRule(ctx context.Context, gymID, ruleID string) error {
+	err := r.Repository.DeleteBadgeRule(ctx, gymID, ruleID)
+	if err == nil {
+		r.invalidateMembersVersion(ctx, gymID)
+	}
+	return err
+}
+
+// invalidateMembersVersion сбрасывает версию списка участников зала: награды
+// входят в ответ, но не хранятся в закэшированном списке
+func (r *Repository) invalidateMembersVersion(ctx context.Context, gymID string) {
+	r.invalidations.Add(1)
+	if err := r.backend.Delete(ctx, membersVersionKey(gymID)); err != nil {
+		r.errors.Add(1)
+		log.Printf("Ошибка сброса версии участников зала %s: %v", gymID, err)
+	}
+}
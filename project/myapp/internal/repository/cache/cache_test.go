@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"myapp/internal/models"
+	"myapp/internal/service"
+	"myapp/internal/tenant"
+)
+
+const (
+	orgA  = "org-a"
+	orgB  = "org-b"
+	gymA  = "gym-a"
+	gymB  = "gym-b"
+	user1 = "user-1"
+)
+
+// fakeRepository - репозиторий с двумя организациями по залу в каждой, который
+// ограничивает запросы организацией из контекста так же, как postgres.Repository.
+// Пользователь user1 состоит в обоих залах.
+type fakeRepository struct {
+	service.Repository
+
+	gyms    map[string]models.Gym
+	members map[string][]models.User
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		gyms: map[string]models.Gym{
+			gymA: {ID: gymA, OrganizationID: orgA, Name: "A"},
+			gymB: {ID: gymB, OrganizationID: orgB, Name: "B"},
+		},
+		members: map[string][]models.User{
+			gymA: {{ID: user1, Status: models.ActiveStatus}},
+			gymB: {{ID: user1, Status: models.ActiveStatus}},
+		},
+	}
+}
+
+// visible проверяет зал так же, как checkGym
+func (f *fakeRepository) visible(ctx context.Context, gymID string) error {
+	gym, ok := f.gyms[gymID]
+	if !ok {
+		return fmt.Errorf("%w: зал не найден", models.ErrNotFound)
+	}
+	if !tenant.AllOrganizations(ctx) && gym.OrganizationID != tenant.OrganizationID(ctx) {
+		return fmt.Errorf("%w: зал не найден", models.ErrNotFound)
+	}
+	return nil
+}
+
+func (f *fakeRepository) GetGym(ctx context.Context, gymID string) (models.Gym, error) {
+	if err := f.visible(ctx, gymID); err != nil {
+		return models.Gym{}, err
+	}
+	return f.gyms[gymID], nil
+}
+
+func (f *fakeRepository) DeleteGym(ctx context.Context, gymID string) error {
+	if err := f.visible(ctx, gymID); err != nil {
+		return err
+	}
+	delete(f.gyms, gymID)
+	delete(f.members, gymID)
+	return nil
+}
+
+func (f *fakeRepository) GetGroupMembers(ctx context.Context, gymID string) ([]models.User, error) {
+	if err := f.visible(ctx, gymID); err != nil {
+		return nil, err
+	}
+	return f.members[gymID], nil
+}
+
+func (f *fakeRepository) GetGroupMembersVersion(ctx context.Context, gymID string) (models.ListVersion, error) {
+	if err := f.visible(ctx, gymID); err != nil {
+		return models.ListVersion{}, err
+	}
+	return models.ListVersion{Hash: gymID}, nil
+}
+
+func (f *fakeRepository) GetUserStatus(ctx context.Context, userID, gymID string) (models.ActivityStatus, error) {
+	if err := f.visible(ctx, gymID); err != nil {
+		return "", err
+	}
+	for _, user := range f.members[gymID] {
+		if user.ID == userID {
+			return user.Status, nil
+		}
+	}
+	return "", fmt.Errorf("%w: пользователь не найден", models.ErrNotFound)
+}
+
+// GetUserGroup возвращает первую по ID группу пользователя среди видимых залов
+func (f *fakeRepository) GetUserGroup(ctx context.Context, userID string) (models.Group, []models.User, error) {
+	for _, gymID := range []string{gymA, gymB} {
+		if f.visible(ctx, gymID) != nil {
+			continue
+		}
+		for _, user := range f.members[gymID] {
+			if user.ID == userID {
+				return models.Group{GymID: gymID, Name: f.gyms[gymID].Name}, f.members[gymID], nil
+			}
+		}
+	}
+	return models.Group{}, nil, fmt.Errorf("%w: пользователь не состоит ни в одной группе", models.ErrNotFound)
+}
+
+func newTestRepository(repo service.Repository) *Repository {
+	return NewRepository(repo, NewLRU(100), time.Minute)
+}
+
+func TestCheckTenantHidesOtherOrganizations(t *testing.T) {
+	repo := newTestRepository(newFakeRepository())
+	ctxA := tenant.WithOrganization(context.Background(), orgA)
+	ctxB := tenant.WithOrganization(context.Background(), orgB)
+
+	// Организация B заполняет кэш своего зала, после чего A все равно не видит его
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetGroupMembers(ctxB, gymB); err != nil {
+			t.Fatalf("GetGroupMembers своего зала: %v", err)
+		}
+
+		if _, err := repo.GetGroupMembers(ctxA, gymB); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetGroupMembers чужого зала: ожидалась ErrNotFound, получено %v", err)
+		}
+		if _, err := repo.GetGroupMembersVersion(ctxA, gymB); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetGroupMembersVersion чужого зала: ожидалась ErrNotFound, получено %v", err)
+		}
+		if _, err := repo.GetUserStatus(ctxA, user1, gymB); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetUserStatus в чужом зале: ожидалась ErrNotFound, получено %v", err)
+		}
+	}
+
+	if _, err := repo.GetGroupMembers(tenant.Unscoped(context.Background()), gymB); err != nil {
+		t.Errorf("GetGroupMembers со всеми организациями: %v", err)
+	}
+	if _, err := repo.GetGroupMembers(context.Background(), gymB); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetGroupMembers без организации: ожидалась ErrNotFound, получено %v", err)
+	}
+}
+
+func TestCheckTenantUnknownGym(t *testing.T) {
+	repo := newTestRepository(newFakeRepository())
+	ctxA := tenant.WithOrganization(context.Background(), orgA)
+
+	if _, err := repo.GetGroupMembers(ctxA, "missing"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("ожидалась ErrNotFound, получено %v", err)
+	}
+}
+
+func TestUserGroupPerOrganization(t *testing.T) {
+	repo := newTestRepository(newFakeRepository())
+	ctxA := tenant.WithOrganization(context.Background(), orgA)
+	ctxB := tenant.WithOrganization(context.Background(), orgB)
+
+	// Повторные чтения идут из кэша и не смешивают группы организаций
+	for i := 0; i < 2; i++ {
+		for _, tc := range []struct {
+			ctx  context.Context
+			want string
+		}{{ctxA, gymA}, {ctxB, gymB}} {
+			group, _, err := repo.GetUserGroup(tc.ctx, user1)
+			if err != nil {
+				t.Fatalf("GetUserGroup в %s: %v", tc.want, err)
+			}
+			if group.GymID != tc.want {
+				t.Errorf("GetUserGroup: ожидался зал %s, получен %s", tc.want, group.GymID)
+			}
+
+			if _, err := repo.GetUserGroupVersion(tc.ctx, user1); err != nil {
+				t.Errorf("GetUserGroupVersion в %s: %v", tc.want, err)
+			}
+		}
+	}
+}
+
+func TestInvalidateMemberResetsOrganizationUserGroup(t *testing.T) {
+	backend := NewLRU(100)
+	repo := NewRepository(newFakeRepository(), backend, time.Minute)
+	ctx := context.Background()
+	ctxB := tenant.WithOrganization(ctx, orgB)
+
+	if _, _, err := repo.GetUserGroup(ctxB, user1); err != nil {
+		t.Fatalf("GetUserGroup: %v", err)
+	}
+	if _, ok, _ := backend.Get(ctx, userGroupKey(orgB, user1)); !ok {
+		t.Fatal("группа пользователя не попала в кэш")
+	}
+
+	repo.InvalidateMember(ctx, user1, gymB)
+
+	if _, ok, _ := backend.Get(ctx, userGroupKey(orgB, user1)); ok {
+		t.Error("группа пользователя в организации зала не сброшена")
+	}
+}
+
+func TestDeleteGymResetsGym(t *testing.T) {
+	repo := newTestRepository(newFakeRepository())
+	ctxA := tenant.WithOrganization(context.Background(), orgA)
+
+	if _, err := repo.GetGroupMembers(ctxA, gymA); err != nil {
+		t.Fatalf("GetGroupMembers: %v", err)
+	}
+	if err := repo.DeleteGym(ctxA, gymA); err != nil {
+		t.Fatalf("DeleteGym: %v", err)
+	}
+
+	if _, err := repo.GetGroupMembers(ctxA, gymA); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetGroupMembers удаленного зала: ожидалась ErrNotFound, получено %v", err)
+	}
+}
+
+func TestReadThroughSurvivesFirstCallerCancel(t *testing.T) {
+	repo := newTestRepository(newFakeRepository())
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		// Отмена первого вызывающего не должна прерывать общую загрузку
+		return "value", ctx.Err()
+	}
+
+	ctx1, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		var value string
+		first <- repo.readThrough(ctx1, "key", &value, load)
+	}()
+	<-started
+
+	second := make(chan error, 1)
+	go func() {
+		var value string
+		second <- repo.readThrough(context.Background(), "key", &value, load)
+	}()
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("отмененный вызывающий: ожидалась context.Canceled, получено %v", err)
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("второй вызывающий: %v", err)
+	}
+}
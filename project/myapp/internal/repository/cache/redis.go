@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis - внешнее хранилище кэша, общее для всех реплик
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis подключается к Redis по URL вида redis://host:port/db.
+// Все ключи сохраняются с префиксом prefix.
+func NewRedis(url, prefix string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Redis{
+		client: redis.NewClient(opts),
+		prefix: prefix,
+	}, nil
+}
+
+// Get возвращает значение, если оно есть
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set сохраняет значение со временем жизни ttl
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+// Delete удаляет значения
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+// Flush удаляет все значения с префиксом кэша
+func (c *Redis) Flush(ctx context.Context) error {
+	iter := c.client.Scan(ctx, 0, c.prefix+"*", 500).Iterator()
+	var batch []string
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == 500 {
+			if err := c.client.Del(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return c.client.Del(ctx, batch...).Err()
+	}
+	return nil
+}
+
+// Close закрывает соединение с Redis
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"myapp/internal/models"
+)
+
+// AuditRepository хранит журнал аудита. Записи только добавляются:
+// изменение и удаление запрещены триггерами таблицы audit_log.
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditRepository создает новый репозиторий журнала аудита
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+const auditColumns = `id, request_id, organization_id, actor_id, actor_role, method, route, target_ids,
+	gym_id, target_user_id, before_value, after_value, status_code, outcome, created_at`
+
+// AppendAuditEntry добавляет запись в журнал
+func (r *AuditRepository) AppendAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (request_id, organization_id, actor_id, actor_role, method, route, target_ids,
+			gym_id, target_user_id, before_value, after_value, status_code, outcome, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.RequestID, entry.OrganizationID, entry.ActorID, entry.ActorRole, entry.Method, entry.Route,
+		entry.TargetIDs, entry.GymID, entry.TargetUserID, entry.Before, entry.After,
+		entry.StatusCode, entry.Outcome, utcNow())
+	return err
+}
+
+// GetGymOrganizationID получает организацию зала без учета организации вызывающего.
+// Для неизвестного зала возвращает пустую строку.
+func (r *AuditRepository) GetGymOrganizationID(ctx context.Context, gymID string) (string, error) {
+	var orgID string
+	err := r.db.GetContext(ctx, &orgID, `SELECT COALESCE(organization_id::text, '') FROM gyms WHERE id = $1`, gymID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return orgID, err
+}
+
+// ListAuditEntries получает записи журнала по фильтру от новых к старым.
+// Вызывающий из организации видит только записи своей организации.
+func (r *AuditRepository) ListAuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if filter.GymID != "" {
+		if err := checkGym(ctx, r.db, filter.GymID); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE TRUE`
+	args := []interface{}{}
+	where := func(condition string, value interface{}) {
+		args = append(args, value)
+		query += fmt.Sprintf(" AND "+condition, len(args))
+	}
+
+	if orgID := tenantFilter(ctx); orgID != allTenants {
+		where("organization_id::text = $%d", orgID)
+	}
+	if filter.ActorID != "" {
+		where("actor_id = $%d", filter.ActorID)
+	}
+	if filter.GymID != "" {
+		where("gym_id = $%d", filter.GymID)
+	}
+	if filter.From != nil {
+		where("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		where("created_at < $%d", *filter.To)
+	}
+	if filter.BeforeID > 0 {
+		where("id < $%d", filter.BeforeID)
+	}
+	query += ` ORDER BY id DESC LIMIT ` + strconv.Itoa(filter.Limit)
+
+	entries := []models.AuditEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,321 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"myapp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const badgeRuleColumns = `id, gym_id, code, title, kind, threshold, created_at`
+
+// RefreshMemberStats пересчитывает посещения и серии участников зала и выдает
+// заработанные награды в одной транзакции. Если userID не пуст, пересчитывается
+// только этот участник. Неделя продлевает серию, если в ней было не меньше
+// minVisits посещений; границы недель берутся в часовом поясе зала.
+// Возвращает число пересчитанных участников и новые награды.
+func (r *Repository) RefreshMemberStats(ctx context.Context, gymID, userID string, minVisits int) (int, []models.Badge, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return 0, nil, err
+	}
+
+	var members int
+	var badges []models.Badge
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var err error
+		members, badges, err = refreshMemberStats(ctx, tx, gymID, userID, minVisits)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return members, badges, nil
+}
+
+// refreshMemberStats пересчитывает сводку участников по всей истории посещений
+// и выдает награды в транзакции tx
+func refreshMemberStats(ctx context.Context, tx *sqlx.Tx, gymID, userID string, minVisits int) (int, []models.Badge, error) {
+	now := utcNow()
+	query := `
+		WITH weekly AS (
+			-- Недели считаются по местному времени зала, чтобы серии не зависели от
+			-- часового пояса сеанса и перехода на летнее время
+			SELECT v.user_id, date_trunc('week', v.visited_at AT TIME ZONE g.timezone) AS week, COUNT(*) AS visits
+			FROM visits v
+			JOIN gyms g ON g.id = v.gym_id
+			WHERE v.gym_id = $1 AND ($2 = '' OR v.user_id::text = $2)
+			GROUP BY v.user_id, week
+		),
+		qualified AS (
+			SELECT user_id, week,
+				week - ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY week) * INTERVAL '1 week' AS island
+			FROM weekly
+			WHERE visits >= $3
+		),
+		islands AS (
+			SELECT user_id, COUNT(*) AS length, MAX(week) AS last_week
+			FROM qualified
+			GROUP BY user_id, island
+		),
+		streaks AS (
+			SELECT user_id, MAX(length) AS longest_streak,
+				(ARRAY_AGG(length ORDER BY last_week DESC))[1] AS current_streak,
+				MAX(last_week) AT TIME ZONE (SELECT timezone FROM gyms WHERE id = $1) AS streak_week
+			FROM islands
+			GROUP BY user_id
+		),
+		totals AS (
+			SELECT user_id, SUM(visits) AS total_visits
+			FROM weekly
+			GROUP BY user_id
+		)
+		INSERT INTO member_stats (user_id, gym_id, total_visits, current_streak, longest_streak, streak_week, updated_at)
+		SELECT t.user_id, $1, t.total_visits, COALESCE(s.current_streak, 0), COALESCE(s.longest_streak, 0), s.streak_week, $4
+		FROM totals t
+		LEFT JOIN streaks s ON s.user_id = t.user_id
+		ON CONFLICT (user_id, gym_id) DO UPDATE
+		SET total_visits = EXCLUDED.total_visits, current_streak = EXCLUDED.current_streak,
+			longest_streak = EXCLUDED.longest_streak, streak_week = EXCLUDED.streak_week,
+			updated_at = EXCLUDED.updated_at
+	`
+	res, err := tx.ExecContext(ctx, query, gymID, userID, minVisits, now)
+	if err != nil {
+		return 0, nil, err
+	}
+	members, err := res.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	badges, err := awardBadges(ctx, tx, gymID, userID, now)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return int(members), badges, nil
+}
+
+// updateMemberStats учитывает в сводке участника новое посещение visitedAt без
+// пересчета всей истории и выдает заработанные награды в транзакции tx. Если сводки
+// еще нет или посещение отмечено задним числом раньше последней засчитанной недели,
+// сводка участника пересчитывается полностью.
+func updateMemberStats(ctx context.Context, tx *sqlx.Tx, gymID, userID string, visitedAt time.Time, minVisits int) ([]models.Badge, error) {
+	var stats models.MemberStats
+	query := `
+		SELECT user_id, gym_id, total_visits, current_streak, longest_streak, streak_week
+		FROM member_stats
+		WHERE gym_id = $1 AND user_id = $2
+		FOR UPDATE
+	`
+	err := tx.GetContext(ctx, &stats, query, gymID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, badges, err := refreshMemberStats(ctx, tx, gymID, userID, minVisits)
+		return badges, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Неделя посещения и предыдущая неделя по местному времени зала, как при пересчете
+	var week struct {
+		Start    time.Time `db:"week_start"`
+		Previous time.Time `db:"previous_week_start"`
+		Visits   int       `db:"week_visits"`
+	}
+	query = `
+		WITH week AS (
+			SELECT date_trunc('week', $3::timestamptz AT TIME ZONE timezone) AS local_start, timezone
+			FROM gyms
+			WHERE id = $1
+		)
+		SELECT w.local_start AT TIME ZONE w.timezone AS week_start,
+			(w.local_start - INTERVAL '1 week') AT TIME ZONE w.timezone AS previous_week_start,
+			(
+				SELECT COUNT(*) FROM visits v
+				WHERE v.gym_id = $1 AND v.user_id = $2
+					AND v.visited_at >= w.local_start AT TIME ZONE w.timezone
+					AND v.visited_at < (w.local_start + INTERVAL '1 week') AT TIME ZONE w.timezone
+			) AS week_visits
+		FROM week w
+	`
+	if err := tx.GetContext(ctx, &week, query, gymID, userID, visitedAt); err != nil {
+		return nil, err
+	}
+
+	if stats.StreakWeek != nil && week.Start.Before(*stats.StreakWeek) {
+		_, badges, err := refreshMemberStats(ctx, tx, gymID, userID, minVisits)
+		return badges, err
+	}
+
+	stats.TotalVisits++
+	// Неделя после последней засчитанной продлевает серию или начинает новую,
+	// как только в ней набирается minVisits посещений
+	if week.Visits >= minVisits && (stats.StreakWeek == nil || week.Start.After(*stats.StreakWeek)) {
+		if stats.StreakWeek != nil && stats.StreakWeek.Equal(week.Previous) {
+			stats.CurrentStreak++
+		} else {
+			stats.CurrentStreak = 1
+		}
+		stats.LongestStreak = max(stats.LongestStreak, stats.CurrentStreak)
+		stats.StreakWeek = &week.Start
+	}
+
+	now := utcNow()
+	query = `
+		UPDATE member_stats
+		SET total_visits = $3, current_streak = $4, longest_streak = $5, streak_week = $6, updated_at = $7
+		WHERE gym_id = $1 AND user_id = $2
+	`
+	_, err = tx.ExecContext(ctx, query, gymID, userID,
+		stats.TotalVisits, stats.CurrentStreak, stats.LongestStreak, stats.StreakWeek, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return awardBadges(ctx, tx, gymID, userID, now)
+}
+
+// awardBadges выдает участникам зала награды по их сводке в транзакции tx.
+// Если userID не пуст, награды выдаются только этому участнику.
+func awardBadges(ctx context.Context, tx *sqlx.Tx, gymID, userID string, now time.Time) ([]models.Badge, error) {
+	query := `
+		WITH awarded AS (
+			INSERT INTO member_badges (user_id, gym_id, rule_id, awarded_at)
+			SELECT ms.user_id, ms.gym_id, br.id, $3
+			FROM member_stats ms
+			JOIN badge_rules br ON br.gym_id IS NULL OR br.gym_id = ms.gym_id
+			WHERE ms.gym_id = $1 AND ($2 = '' OR ms.user_id::text = $2)
+				AND ((br.kind = $4 AND ms.total_visits >= br.threshold)
+					OR (br.kind = $5 AND ms.longest_streak >= br.threshold))
+			ON CONFLICT DO NOTHING
+			RETURNING user_id, rule_id, awarded_at
+		)
+		SELECT a.user_id, br.code, br.title, a.awarded_at
+		FROM awarded a
+		JOIN badge_rules br ON br.id = a.rule_id
+		ORDER BY a.user_id, br.kind, br.threshold
+	`
+	badges := []models.Badge{}
+	if err := tx.SelectContext(ctx, &badges, query, gymID, userID, now, models.VisitsBadge, models.StreakBadge); err != nil {
+		return nil, err
+	}
+
+	return badges, nil
+}
+
+// GetMemberStats получает посещения и серии участника зала.
+// Участник без посещений получает нулевую статистику.
+func (r *Repository) GetMemberStats(ctx context.Context, gymID, userID string) (models.MemberStats, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.MemberStats{}, err
+	}
+
+	query := `
+		SELECT user_id, gym_id, total_visits, current_streak, longest_streak, streak_week
+		FROM member_stats
+		WHERE gym_id = $1 AND user_id = $2
+	`
+
+	stats := models.MemberStats{UserID: userID, GymID: gymID}
+	if err := r.db.GetContext(ctx, &stats, query, gymID, userID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.MemberStats{}, err
+	}
+
+	return stats, nil
+}
+
+// ListMemberBadges получает награды участников зала. Если userID не пуст,
+// возвращаются только награды этого участника.
+func (r *Repository) ListMemberBadges(ctx context.Context, gymID, userID string) ([]models.Badge, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT mb.user_id, br.code, br.title, mb.awarded_at
+		FROM member_badges mb
+		JOIN badge_rules br ON br.id = mb.rule_id
+		WHERE mb.gym_id = $1 AND ($2 = '' OR mb.user_id::text = $2)
+		ORDER BY mb.user_id, mb.awarded_at, br.code
+	`
+
+	badges := []models.Badge{}
+	if err := r.db.SelectContext(ctx, &badges, query, gymID, userID); err != nil {
+		return nil, err
+	}
+
+	return badges, nil
+}
+
+// ListBadgeRules получает правила наград, действующие в зале
+func (r *Repository) ListBadgeRules(ctx context.Context, gymID string) ([]models.BadgeRule, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + badgeRuleColumns + `
+		FROM badge_rules
+		WHERE gym_id IS NULL OR gym_id = $1
+		ORDER BY kind, threshold, code
+	`
+
+	rules := []models.BadgeRule{}
+	if err := r.db.SelectContext(ctx, &rules, query, gymID); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// CreateBadgeRule сохраняет правило награды зала
+func (r *Repository) CreateBadgeRule(ctx context.Context, rule models.BadgeRule) (models.BadgeRule, error) {
+	// Общие правила без зала создает только администратор платформы
+	var gymID string
+	if rule.GymID != nil {
+		gymID = *rule.GymID
+	}
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.BadgeRule{}, err
+	}
+
+	query := `
+		INSERT INTO badge_rules (gym_id, code, title, kind, threshold, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + badgeRuleColumns
+
+	var created models.BadgeRule
+	err := r.db.GetContext(ctx, &created, query, rule.GymID, rule.Code, rule.Title, rule.Kind, rule.Threshold, utcNow())
+	if isUniqueViolation(err) {
+		return models.BadgeRule{}, fmt.Errorf("%w: награда с таким кодом уже существует", models.ErrConflict)
+	}
+
+	return created, err
+}
+
+// DeleteBadgeRule удаляет правило награды зала вместе с выданными по нему наградами.
+// Общие правила удалить через зал нельзя.
+func (r *Repository) DeleteBadgeRule(ctx context.Context, gymID, ruleID string) error {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM badge_rules WHERE gym_id = $1 AND id = $2`, gymID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return notFound(sql.ErrNoRows, "правило награды не найдено")
+	}
+
+	return nil
+}
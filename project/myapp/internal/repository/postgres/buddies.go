@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"myapp/internal/models"
+)
+
+// GetBuddyPreferences получает настройки подбора партнеров участника.
+// Если участник их не менял, подбор выключен.
+func (r *Repository) GetBuddyPreferences(ctx context.Context, gymID, userID string) (models.BuddyPreferences, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.BuddyPreferences{}, err
+	}
+
+	query := `
+		SELECT opted_in, hidden, updated_at
+		FROM buddy_preferences
+		WHERE gym_id = $1 AND user_id = $2
+	`
+
+	var prefs models.BuddyPreferences
+	err := r.db.GetContext(ctx, &prefs, query, gymID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.BuddyPreferences{}, nil
+	}
+
+	return prefs, err
+}
+
+// UpdateBuddyPreferences сохраняет настройки подбора партнеров участника
+func (r *Repository) UpdateBuddyPreferences(ctx context.Context, gymID, userID string, prefs models.BuddyPreferences) (models.BuddyPreferences, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.BuddyPreferences{}, err
+	}
+
+	query := `
+		INSERT INTO buddy_preferences (user_id, gym_id, opted_in, hidden, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, gym_id) DO UPDATE
+		SET opted_in = EXCLUDED.opted_in, hidden = EXCLUDED.hidden, updated_at = EXCLUDED.updated_at
+		RETURNING opted_in, hidden, updated_at
+	`
+
+	var updated models.BuddyPreferences
+	err := r.db.GetContext(ctx, &updated, query, userID, gymID, prefs.OptedIn, prefs.Hidden, utcNow())
+	return updated, err
+}
+
+// ListBuddySuggestions подбирает участников зала, чьи посещения с since похожи на посещения userID.
+// Для каждого участника строятся распределения посещений по дням недели и часам суток
+// в часовом поясе зала; похожесть - косинусная мера между распределениями. Соседние часы
+// засчитываются с половинным весом, чтобы 18:00 и 19:00 считались близкими.
+// Предлагаются только участники, включившие подбор и не скрытые.
+func (r *Repository) ListBuddySuggestions(ctx context.Context, gymID, userID, timezone string, since time.Time, limit int) ([]models.BuddySuggestion, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH candidates AS (
+			SELECT bp.user_id
+			FROM buddy_preferences bp
+			JOIN group_members gm ON gm.user_id = bp.user_id AND gm.gym_id = bp.gym_id
+			WHERE bp.gym_id = $1 AND bp.opted_in AND NOT bp.hidden
+				AND gm.status = ANY($5) AND bp.user_id::text <> $2
+		),
+		recent AS (
+			SELECT v.user_id,
+				EXTRACT(ISODOW FROM v.visited_at AT TIME ZONE $3)::int AS dow,
+				EXTRACT(HOUR FROM v.visited_at AT TIME ZONE $3)::int AS hour
+			FROM visits v
+			WHERE v.gym_id = $1 AND v.visited_at >= $4
+				AND (v.user_id::text = $2 OR v.user_id IN (SELECT user_id FROM candidates))
+		),
+		dows AS (
+			SELECT user_id, dow AS bucket, COUNT(*)::float8 AS weight
+			FROM recent
+			GROUP BY user_id, dow
+		),
+		hours AS (
+			SELECT user_id, bucket, SUM(weight) AS weight
+			FROM (
+				SELECT user_id, hour AS bucket, 1.0::float8 AS weight FROM recent
+				UNION ALL
+				SELECT user_id, (hour + 1) % 24, 0.5 FROM recent
+				UNION ALL
+				SELECT user_id, (hour + 23) % 24, 0.5 FROM recent
+			) spread
+			GROUP BY user_id, bucket
+		),
+		dow_norms AS (
+			SELECT user_id, SQRT(SUM(weight * weight)) AS norm FROM dows GROUP BY user_id
+		),
+		hour_norms AS (
+			SELECT user_id, SQRT(SUM(weight * weight)) AS norm FROM hours GROUP BY user_id
+		),
+		dow_scores AS (
+			SELECT o.user_id, SUM(me.weight * o.weight) / (mn.norm * onr.norm) AS score
+			FROM dows me
+			JOIN dows o ON o.bucket = me.bucket AND o.user_id <> me.user_id
+			JOIN dow_norms mn ON mn.user_id = me.user_id
+			JOIN dow_norms onr ON onr.user_id = o.user_id
+			WHERE me.user_id::text = $2
+			GROUP BY o.user_id, mn.norm, onr.norm
+		),
+		hour_scores AS (
+			SELECT o.user_id, SUM(me.weight * o.weight) / (mn.norm * onr.norm) AS score
+			FROM hours me
+			JOIN hours o ON o.bucket = me.bucket AND o.user_id <> me.user_id
+			JOIN hour_norms mn ON mn.user_id = me.user_id
+			JOIN hour_norms onr ON onr.user_id = o.user_id
+			WHERE me.user_id::text = $2
+			GROUP BY o.user_id, mn.norm, onr.norm
+		)
+		SELECT c.user_id, u.first_name, u.last_name,
+			(COALESCE(d.score, 0) + COALESCE(h.score, 0)) / 2 AS score,
+			COALESCE(d.score, 0) AS weekday_score,
+			COALESCE(h.score, 0) AS time_score
+		FROM candidates c
+		JOIN users u ON u.id = c.user_id
+		LEFT JOIN dow_scores d ON d.user_id = c.user_id
+		LEFT JOIN hour_scores h ON h.user_id = c.user_id
+		WHERE COALESCE(d.score, 0) + COALESCE(h.score, 0) > 0
+		ORDER BY score DESC, c.user_id
+		LIMIT $6
+	`
+
+	suggestions := []models.BuddySuggestion{}
+	err := r.db.SelectContext(ctx, &suggestions, query, gymID, userID, timezone, since, memberStatuses(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,358 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"myapp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const classColumns = `
+	id, gym_id, title, description, to_char(starts_at, 'YYYY-MM-DD"T"HH24:MI') AS starts_at,
+	duration_minutes, rrule, capacity, created_by, created_at, updated_at
+`
+
+// CreateClass сохраняет новое занятие зала
+func (r *Repository) CreateClass(ctx context.Context, class models.Class) (models.Class, error) {
+	if err := checkGym(ctx, r.db, class.GymID); err != nil {
+		return models.Class{}, err
+	}
+
+	query := `
+		INSERT INTO classes (gym_id, title, description, starts_at, duration_minutes, rrule, capacity, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::timestamp, $5, $6, $7, $8, $9, $9)
+		RETURNING ` + classColumns
+
+	var created models.Class
+	err := r.db.GetContext(ctx, &created, query,
+		class.GymID, class.Title, class.Description, class.StartsAt, class.DurationMinutes,
+		class.RRule, class.Capacity, class.CreatedBy, utcNow())
+	return created, err
+}
+
+// GetClass получает занятие зала по ID
+func (r *Repository) GetClass(ctx context.Context, gymID, classID string) (models.Class, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.Class{}, err
+	}
+
+	query := `SELECT ` + classColumns + ` FROM classes WHERE gym_id = $1 AND id = $2`
+
+	var class models.Class
+	if err := r.db.GetContext(ctx, &class, query, gymID, classID); err != nil {
+		return models.Class{}, notFound(err, "занятие не найдено")
+	}
+
+	return class, nil
+}
+
+// ListClasses получает занятия зала
+func (r *Repository) ListClasses(ctx context.Context, gymID string) ([]models.Class, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + classColumns + ` FROM classes WHERE gym_id = $1 ORDER BY starts_at, id`
+
+	classes := []models.Class{}
+	if err := r.db.SelectContext(ctx, &classes, query, gymID); err != nil {
+		return nil, err
+	}
+
+	return classes, nil
+}
+
+// UpdateClass сохраняет изменения занятия. Расписание нельзя изменить, пока на будущие
+// занятия серии есть записи. При увеличении вместимости места будущих занятий
+// отдаются участникам из листа ожидания.
+func (r *Repository) UpdateClass(ctx context.Context, class models.Class) (models.Class, error) {
+	if err := checkGym(ctx, r.db, class.GymID); err != nil {
+		return models.Class{}, err
+	}
+
+	var updated models.Class
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var current models.Class
+		query := `SELECT ` + classColumns + ` FROM classes WHERE gym_id = $1 AND id = $2 FOR UPDATE`
+		if err := tx.GetContext(ctx, &current, query, class.GymID, class.ID); err != nil {
+			return notFound(err, "занятие не найдено")
+		}
+
+		now := utcNow()
+		if current.StartsAt != class.StartsAt || current.RRule != class.RRule {
+			var booked bool
+			err := tx.GetContext(ctx, &booked, `
+				SELECT EXISTS (
+					SELECT 1
+					FROM class_sessions s
+					JOIN class_bookings b ON b.session_id = s.id
+					WHERE s.class_id = $1 AND s.starts_at > $2 AND b.status <> $3
+				)
+			`, class.ID, now, models.BookingCancelled)
+			if err != nil {
+				return err
+			}
+			if booked {
+				return fmt.Errorf("%w: нельзя изменить расписание занятия, на которое есть записи", models.ErrConflict)
+			}
+		}
+
+		query = `
+			UPDATE classes
+			SET title = $1, description = $2, starts_at = $3::timestamp, duration_minutes = $4,
+				rrule = $5, capacity = $6, updated_at = $7
+			WHERE id = $8
+			RETURNING ` + classColumns
+		err := tx.GetContext(ctx, &updated, query,
+			class.Title, class.Description, class.StartsAt, class.DurationMinutes,
+			class.RRule, class.Capacity, now, class.ID)
+		if err != nil {
+			return err
+		}
+
+		if updated.Capacity <= current.Capacity {
+			return nil
+		}
+
+		var sessions []string
+		query = `SELECT id FROM class_sessions WHERE class_id = $1 AND starts_at > $2 ORDER BY starts_at FOR UPDATE`
+		if err := tx.SelectContext(ctx, &sessions, query, class.ID, now); err != nil {
+			return err
+		}
+		for _, sessionID := range sessions {
+			if err := promoteWaitlist(ctx, tx, sessionID, updated.Capacity, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Class{}, err
+	}
+
+	return updated, nil
+}
+
+// DeleteClass удаляет занятие зала вместе с записями на него
+func (r *Repository) DeleteClass(ctx context.Context, gymID, classID string) error {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM classes WHERE gym_id = $1 AND id = $2`, gymID, classID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return notFound(sql.ErrNoRows, "занятие не найдено")
+	}
+
+	return nil
+}
+
+// ListSessionCounts получает заполненность занятий зала, начинающихся в [from, to),
+// и запись userID на каждое из них
+func (r *Repository) ListSessionCounts(ctx context.Context, gymID string, from, to time.Time, userID string) ([]models.SessionCounts, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT s.class_id, s.starts_at,
+			COUNT(b.id) FILTER (WHERE b.status = $5) AS booked,
+			COUNT(b.id) FILTER (WHERE b.status = $6) AS waitlisted,
+			MAX(b.status) FILTER (WHERE b.user_id::text = $4 AND b.status <> $7) AS my_status
+		FROM class_sessions s
+		JOIN classes c ON c.id = s.class_id
+		LEFT JOIN class_bookings b ON b.session_id = s.id
+		WHERE c.gym_id = $1 AND s.starts_at >= $2 AND s.starts_at < $3
+		GROUP BY s.class_id, s.starts_at
+	`
+
+	counts := []models.SessionCounts{}
+	err := r.db.SelectContext(ctx, &counts, query, gymID, from, to, userID,
+		models.BookingConfirmed, models.BookingWaitlisted, models.BookingCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// BookSession записывает пользователя на занятие серии, начинающееся в startsAt.
+// Занятие блокируется на время записи, поэтому одновременные запросы не превысят вместимость:
+// при отсутствии мест пользователь попадает в лист ожидания.
+func (r *Repository) BookSession(ctx context.Context, classID string, startsAt time.Time, userID string) (models.Booking, error) {
+	var booking models.Booking
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		sessionID, capacity, err := lockSession(ctx, tx, classID, startsAt, true)
+		if err != nil {
+			return err
+		}
+
+		var confirmed int
+		query := `SELECT COUNT(*) FROM class_bookings WHERE session_id = $1 AND status = $2`
+		if err := tx.GetContext(ctx, &confirmed, query, sessionID, models.BookingConfirmed); err != nil {
+			return err
+		}
+
+		status := models.BookingConfirmed
+		if confirmed >= capacity {
+			status = models.BookingWaitlisted
+		}
+
+		query = `
+			INSERT INTO class_bookings (session_id, user_id, status, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+			RETURNING id, user_id, status, created_at
+		`
+		err = tx.GetContext(ctx, &booking, query, sessionID, userID, status, utcNow())
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: пользователь уже записан на это занятие", models.ErrConflict)
+		}
+		if err != nil {
+			return err
+		}
+		booking.ClassID = classID
+		booking.StartsAt = startsAt
+
+		if status == models.BookingWaitlisted {
+			booking.Position, err = waitlistPosition(ctx, tx, sessionID, booking.ID)
+		}
+		return err
+	})
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	return booking, nil
+}
+
+// CancelBooking отменяет запись пользователя на занятие и отдает освободившиеся места
+// участникам из листа ожидания в порядке очереди
+func (r *Repository) CancelBooking(ctx context.Context, classID string, startsAt time.Time, userID string) (models.Booking, error) {
+	var booking models.Booking
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		sessionID, capacity, err := lockSession(ctx, tx, classID, startsAt, false)
+		if err != nil {
+			return err
+		}
+
+		now := utcNow()
+		query := `
+			UPDATE class_bookings
+			SET status = $1, updated_at = $2
+			WHERE session_id = $3 AND user_id = $4 AND status <> $1
+			RETURNING id, user_id, status, created_at
+		`
+		if err := tx.GetContext(ctx, &booking, query, models.BookingCancelled, now, sessionID, userID); err != nil {
+			return notFound(err, "запись на занятие не найдена")
+		}
+		booking.ClassID = classID
+		booking.StartsAt = startsAt
+
+		return promoteWaitlist(ctx, tx, sessionID, capacity, now)
+	})
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	return booking, nil
+}
+
+// lockSession блокирует занятие серии и возвращает его ID и вместимость.
+// При create занятие создается, если на него еще никто не записывался.
+// Занятия залов других организаций считаются несуществующими.
+func lockSession(ctx context.Context, tx *sqlx.Tx, classID string, startsAt time.Time, create bool) (string, int, error) {
+	if err := checkClass(ctx, tx, classID); err != nil {
+		return "", 0, err
+	}
+
+	if create {
+		query := `
+			INSERT INTO class_sessions (class_id, starts_at)
+			VALUES ($1, $2)
+			ON CONFLICT (class_id, starts_at) DO NOTHING
+		`
+		if _, err := tx.ExecContext(ctx, query, classID, startsAt); err != nil {
+			return "", 0, err
+		}
+	}
+
+	var session struct {
+		ID       string `db:"id"`
+		Capacity int    `db:"capacity"`
+	}
+	query := `
+		SELECT s.id, c.capacity
+		FROM class_sessions s
+		JOIN classes c ON c.id = s.class_id
+		WHERE s.class_id = $1 AND s.starts_at = $2
+		FOR UPDATE OF s
+	`
+	err := tx.GetContext(ctx, &session, query, classID, startsAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, fmt.Errorf("%w: запись на занятие не найдена", models.ErrNotFound)
+	}
+
+	return session.ID, session.Capacity, err
+}
+
+// checkClass проверяет, что занятие принадлежит залу организации из контекста
+func checkClass(ctx context.Context, tx *sqlx.Tx, classID string) error {
+	var gymID string
+	err := tx.GetContext(ctx, &gymID, `SELECT gym_id FROM classes WHERE id = $1`, classID)
+	if err != nil {
+		return notFound(err, "занятие не найдено")
+	}
+
+	if err := checkGym(ctx, tx, gymID); errors.Is(err, models.ErrNotFound) {
+		return fmt.Errorf("%w: занятие не найдено", models.ErrNotFound)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// promoteWaitlist переводит участников из листа ожидания на свободные места
+// в порядке постановки в очередь. Занятие должно быть заблокировано.
+func promoteWaitlist(ctx context.Context, tx *sqlx.Tx, sessionID string, capacity int, now time.Time) error {
+	query := `
+		UPDATE class_bookings
+		SET status = $1, updated_at = $2
+		WHERE id IN (
+			SELECT id
+			FROM class_bookings
+			WHERE session_id = $3 AND status = $4
+			ORDER BY created_at, id
+			LIMIT GREATEST($5 - (
+				SELECT COUNT(*) FROM class_bookings WHERE session_id = $3 AND status = $1
+			), 0)
+		)
+	`
+	_, err := tx.ExecContext(ctx, query, models.BookingConfirmed, now, sessionID, models.BookingWaitlisted, capacity)
+	return err
+}
+
+// waitlistPosition возвращает место записи в листе ожидания, начиная с 1
+func waitlistPosition(ctx context.Context, tx *sqlx.Tx, sessionID, bookingID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM class_bookings w, class_bookings b
+		WHERE b.id = $2 AND w.session_id = $1 AND w.status = $3
+			AND (w.created_at, w.id) <= (b.created_at, b.id)
+	`
+
+	var position int
+	err := tx.GetContext(ctx, &position, query, sessionID, bookingID, models.BookingWaitlisted)
+	return position, err
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"myapp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const gymColumns = `id, organization_id, name, address, latitude, longitude, timezone, opening_hours, created_at, updated_at`
+
+// CreateGym сохраняет новый зал организации вместе с его группой
+func (r *Repository) CreateGym(ctx context.Context, gym models.Gym) (models.Gym, error) {
+	var created models.Gym
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		now := utcNow()
+		query := `
+			INSERT INTO gyms (organization_id, name, address, latitude, longitude, timezone, opening_hours, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+			RETURNING ` + gymColumns
+		err := tx.GetContext(ctx, &created, query,
+			gym.OrganizationID, gym.Name, gym.Address, gym.Latitude, gym.Longitude, gym.Timezone, gym.OpeningHours, now)
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("%w: организация не найдена", models.ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO groups (gym_id, name, created_at) VALUES ($1, $2, $3)`,
+			created.ID, created.Name, now)
+		return err
+	})
+	if err != nil {
+		return models.Gym{}, err
+	}
+
+	return created, nil
+}
+
+// GetGym получает зал организации по ID
+func (r *Repository) GetGym(ctx context.Context, gymID string) (models.Gym, error) {
+	query := `SELECT ` + gymColumns + ` FROM gyms WHERE id = $1 AND ($2 = '*' OR organization_id::text = $2)`
+
+	var gym models.Gym
+	if err := r.db.GetContext(ctx, &gym, query, gymID, tenantFilter(ctx)); err != nil {
+		return models.Gym{}, notFound(err, "зал не найден")
+	}
+
+	return gym, nil
+}
+
+// ListGyms получает залы организации или все залы для контекста с доступом ко всем организациям
+func (r *Repository) ListGyms(ctx context.Context) ([]models.Gym, error) {
+	query := `
+		SELECT ` + gymColumns + `
+		FROM gyms
+		WHERE $1 = '*' OR organization_id::text = $1
+		ORDER BY name, id
+	`
+
+	gyms := []models.Gym{}
+	if err := r.db.SelectContext(ctx, &gyms, query, tenantFilter(ctx)); err != nil {
+		return nil, err
+	}
+
+	return gyms, nil
+}
+
+// UpdateGym сохраняет изменения зала. Группа зала переименовывается вместе с ним.
+// Зал не переходит в другую организацию.
+func (r *Repository) UpdateGym(ctx context.Context, gym models.Gym) (models.Gym, error) {
+	var updated models.Gym
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE gyms
+			SET name = $1, address = $2, latitude = $3, longitude = $4, timezone = $5,
+				opening_hours = $6, updated_at = $7
+			WHERE id = $8 AND ($9 = '*' OR organization_id::text = $9)
+			RETURNING ` + gymColumns
+		err := tx.GetContext(ctx, &updated, query,
+			gym.Name, gym.Address, gym.Latitude, gym.Longitude, gym.Timezone, gym.OpeningHours, utcNow(), gym.ID,
+			tenantFilter(ctx))
+		if err != nil {
+			return notFound(err, "зал не найден")
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE groups SET name = $1 WHERE gym_id = $2`, updated.Name, updated.ID)
+		return err
+	})
+	if err != nil {
+		return models.Gym{}, err
+	}
+
+	return updated, nil
+}
+
+// DeleteGym удаляет зал вместе с его группой. Зал, в котором есть участники, удалить нельзя;
+// записи бывших участников и отклоненных заявок удаляются вместе с залом.
+func (r *Repository) DeleteGym(ctx context.Context, gymID string) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Блокировка зала не дает добавить участника, пока проверяется состав группы
+		var id string
+		query := `SELECT id FROM gyms WHERE id = $1 AND ($2 = '*' OR organization_id::text = $2) FOR UPDATE`
+		if err := tx.GetContext(ctx, &id, query, gymID, tenantFilter(ctx)); err != nil {
+			return notFound(err, "зал не найден")
+		}
+
+		var members int
+		query = `SELECT COUNT(*) FROM group_members WHERE gym_id = $1 AND status = ANY($2)`
+		if err := tx.GetContext(ctx, &members, query, gymID, memberStatuses()); err != nil {
+			return err
+		}
+		if members > 0 {
+			return fmt.Errorf("%w: в зале есть участники", models.ErrConflict)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM group_members WHERE gym_id = $1`, gymID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM gyms WHERE id = $1`, gymID)
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("%w: в зале есть участники", models.ErrConflict)
+		}
+		return err
+	})
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"myapp/internal/models"
+)
+
+// IdempotencyRepository хранит ключи идемпотентности и ответы на запросы с ними
+type IdempotencyRepository struct {
+	db *sqlx.DB
+}
+
+// NewIdempotencyRepository создает новый репозиторий ключей идемпотентности
+func NewIdempotencyRepository(db *sqlx.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: db,
+	}
+}
+
+const idempotencyColumns = `actor_id, key, request_hash, status_code, content_type, response_body,
+	response_headers, locked_until, created_at, expires_at`
+
+// ReserveIdempotencyKey захватывает ключ для выполнения запроса и возвращает true.
+// Если ключ уже занят, возвращает существующую запись и false. Истекший ключ и ключ,
+// запрос с которым не завершился до LockedUntil, захватываются заново.
+// Одновременные вызовы с одним ключом упорядочиваются первичным ключом таблицы.
+func (r *IdempotencyRepository) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	now := utcNow()
+	query := `
+		INSERT INTO idempotency_keys (actor_id, key, request_hash, locked_until, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (actor_id, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = NULL,
+			response_body = NULL, response_headers = NULL, locked_until = EXCLUDED.locked_until,
+			created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= $5
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= $5)
+		RETURNING ` + idempotencyColumns
+
+	var reserved models.IdempotencyRecord
+	err := r.db.GetContext(ctx, &reserved, query,
+		record.ActorID, record.Key, record.RequestHash, record.LockedUntil, now, record.ExpiresAt)
+	if err == nil {
+		return reserved, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	// Ключ занят действующей записью
+	var existing models.IdempotencyRecord
+	err = r.db.GetContext(ctx, &existing,
+		`SELECT `+idempotencyColumns+` FROM idempotency_keys WHERE actor_id = $1 AND key = $2`,
+		record.ActorID, record.Key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Запись удалили между запросами - пробуем захватить ключ снова
+		return r.ReserveIdempotencyKey(ctx, record)
+	}
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ на запрос с захваченным ключом
+func (r *IdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3, response_headers = $7
+		WHERE actor_id = $4 AND key = $5 AND request_hash = $6 AND status_code IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		record.StatusCode, record.ContentType, record.ResponseBody, record.ResponseHeaders,
+		record.ActorID, record.Key, record.RequestHash)
+	return err
+}
+
+// ExtendIdempotencyKey продлевает захват ключа запросом, который еще выполняется
+func (r *IdempotencyRepository) ExtendIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET locked_until = $1
+		WHERE actor_id = $2 AND key = $3 AND request_hash = $4 AND status_code IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, record.LockedUntil, record.ActorID, record.Key, record.RequestHash)
+	return err
+}
+
+// ReleaseIdempotencyKey освобождает ключ запроса, ответ на который не сохраняется,
+// чтобы клиент мог повторить запрос
+func (r *IdempotencyRepository) ReleaseIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE actor_id = $1 AND key = $2 AND request_hash = $3 AND status_code IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, record.ActorID, record.Key, record.RequestHash)
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys удаляет ключи, срок хранения которых истек раньше before
+func (r *IdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"myapp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ImportMembers добавляет или обновляет пользователей и их участие в зале в одной транзакции.
+// Каждая строка выполняется в своей точке сохранения, поэтому ошибка строки попадает
+// в отчет и не отменяет остальные. При dryRun транзакция откатывается.
+// check получает текущий и новый статус существующего участника и решает, допустим ли переход.
+// Изменения статусов записываются в историю от имени actor.
+func (r *Repository) ImportMembers(ctx context.Context, actor models.Actor, gymID string, rows []models.ImportMemberRow, dryRun bool, check func(from, to models.ActivityStatus) error) ([]models.ImportRowReport, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	reports := make([]models.ImportRowReport, len(rows))
+	for i, row := range rows {
+		reports[i] = models.ImportRowReport{Row: i + 1, UserID: row.ID, Email: row.Email}
+
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+			return nil, err
+		}
+
+		result, err := r.importRow(ctx, tx, actor, gymID, row, check)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_row`); rbErr != nil {
+				return nil, rbErr
+			}
+			reports[i].Result = models.ImportFailed
+			reports[i].Error = importError(err)
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT import_row`); err != nil {
+			return nil, err
+		}
+		reports[i].Result = result
+	}
+
+	if dryRun {
+		return reports, nil
+	}
+
+	return reports, tx.Commit()
+}
+
+// importRow добавляет или обновляет пользователя и его участие в зале.
+// Новый пользователь создается; данные существующего меняются, только если он
+// связан с этим залом и не состоит в залах других организаций, иначе строка
+// отклоняется: импорт не должен переписывать учетные записи, общие с другими
+// организациями, в том числе email, к которому привязаны приглашения.
+func (r *Repository) importRow(ctx context.Context, tx *sqlx.Tx, actor models.Actor, gymID string, row models.ImportMemberRow, check func(from, to models.ActivityStatus) error) (models.ImportResult, error) {
+	now := utcNow()
+
+	var previous models.ActivityStatus
+	err := tx.GetContext(ctx, &previous, `
+		SELECT status
+		FROM group_members
+		WHERE user_id = $1 AND gym_id = $2
+		FOR UPDATE
+	`, row.ID, gymID)
+	known := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	userChanged := false
+	if known {
+		var profile struct {
+			Differs bool `db:"differs"`
+			Shared  bool `db:"shared"`
+		}
+		query := `
+			SELECT (email, first_name, last_name) IS DISTINCT FROM ($2, $3, $4) AS differs,
+				app_user_in_other_organization(id, $5) AS shared
+			FROM users
+			WHERE id = $1
+		`
+		if err := tx.GetContext(ctx, &profile, query, row.ID, row.Email, row.FirstName, row.LastName, gymID); err != nil {
+			return "", err
+		}
+		if profile.Differs && profile.Shared {
+			return "", fmt.Errorf("%w: пользователь состоит в залах других организаций, его данные не изменяются", models.ErrConflict)
+		}
+
+		// Пользователь изменяется, только если его данные отличаются
+		if profile.Differs {
+			query = `
+				UPDATE users
+				SET email = $2, first_name = $3, last_name = $4, updated_at = $5
+				WHERE id = $1
+			`
+			if _, err := tx.ExecContext(ctx, query, row.ID, row.Email, row.FirstName, row.LastName, now); err != nil {
+				return "", err
+			}
+			userChanged = true
+		}
+	} else {
+		// Без RETURNING: в режиме RLS новый пользователь еще не виден политике чтения,
+		// пока не вступит в зал
+		query := `
+			INSERT INTO users (id, email, first_name, last_name, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+			ON CONFLICT (id) DO NOTHING
+		`
+		res, err := tx.ExecContext(ctx, query, row.ID, row.Email, row.FirstName, row.LastName, now)
+		if err != nil {
+			return "", err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return "", err
+		}
+		if n == 0 {
+			return "", fmt.Errorf("%w: пользователь с этим ID уже существует и не связан с залом", models.ErrConflict)
+		}
+	}
+
+	change := models.StatusChange{Status: row.Status, ChangedBy: actor.UserID, ChangedByRole: actor.Role}
+
+	switch {
+	case !known:
+		_, err := r.joinMember(ctx, tx, row.ID, gymID, change, now)
+		return models.ImportCreated, err
+
+	case previous != row.Status:
+		if err := check(previous, row.Status); err != nil {
+			return "", err
+		}
+		if previous == models.LeftStatus {
+			_, err := r.joinMember(ctx, tx, row.ID, gymID, change, now)
+			return models.ImportUpdated, err
+		}
+		_, err := r.changeStatus(ctx, tx, row.ID, gymID, previous, change, now)
+		return models.ImportUpdated, err
+
+	case userChanged:
+		return models.ImportUpdated, nil
+
+	default:
+		return models.ImportUnchanged, nil
+	}
+}
+
+// importError возвращает понятное описание ошибки строки импорта
+func importError(err error) string {
+	if isUniqueViolation(err) {
+		return "email уже используется другим пользователем"
+	}
+	return err.Error()
+}
+
+// StreamGroupMembers передает участников зала в fn по одному, не загружая весь список в память
+func (r *Repository) StreamGroupMembers(ctx context.Context, gymID string, fn func(models.User) error) error {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return err
+	}
+
+	query := `
+		SELECT u.id, u.email, u.first_name, u.last_name, gm.status, u.created_at, u.updated_at
+		FROM users u
+		JOIN group_members gm ON u.id = gm.user_id
+		WHERE gm.gym_id = $1
+		ORDER BY gm.joined_at, u.id
+	`
+
+	// Строки читаются в транзакции, чтобы в режиме RLS настройки действовали до конца выборки
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		rows, err := tx.QueryxContext(ctx, query, gymID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var user models.User
+			if err := rows.StructScan(&user); err != nil {
+				return err
+			}
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"fmt"
+	"testing"
+
+	"myapp/internal/models"
+)
+
+// rlsTestRole - роль, под которой тесты выполняют запросы в режиме RLS (миграция 014)
+const rlsTestRole = "group_service_app"
+
+// importActor - администратор, от имени которого тесты выполняют импорт
+var importActor = models.Actor{UserID: "import-admin", Role: models.RoleAdmin}
+
+func TestImportMembers(t *testing.T) {
+	allowAll := func(from, to models.ActivityStatus) error { return nil }
+
+	for _, rls := range []bool{false, true} {
+		t.Run(fmt.Sprintf("rls=%t", rls), func(t *testing.T) {
+			f := newTenantFixture(t)
+			repo := f.repo
+			if rls {
+				repo = NewRepository(f.db, nil)
+				repo.EnableRowLevelSecurity(rlsTestRole)
+			}
+
+			newUser := f.newUserID(t)
+			rows := []models.ImportMemberRow{
+				{ID: newUser, Email: "import-" + f.suffix + "@example.com", FirstName: "New", LastName: "Member", Status: models.ActiveStatus},
+				{ID: f.userB, Email: "hijack-" + f.suffix + "@example.com", FirstName: "Hijacked", LastName: "User", Status: models.ActiveStatus},
+			}
+
+			reports, err := repo.ImportMembers(f.ctxA, importActor, f.gymA.ID, rows, false, allowAll)
+			if err != nil {
+				t.Fatalf("ImportMembers: %v", err)
+			}
+			if reports[0].Result != models.ImportCreated {
+				t.Errorf("новый пользователь: ожидался результат %s, получен %s (%s)", models.ImportCreated, reports[0].Result, reports[0].Error)
+			}
+			if reports[1].Result != models.ImportFailed {
+				t.Errorf("пользователь другой организации: ожидался результат %s, получен %s", models.ImportFailed, reports[1].Result)
+			}
+
+			var email string
+			if err := f.db.Get(&email, `SELECT email FROM users WHERE id = $1`, f.userB); err != nil {
+				t.Fatalf("чтение пользователя B: %v", err)
+			}
+			if email != f.emailB {
+				t.Errorf("импорт организации A изменил email пользователя B: %s", email)
+			}
+
+			// Повторный импорт меняет данные участника своего зала
+			rows = rows[:1]
+			rows[0].FirstName = "Renamed"
+			reports, err = repo.ImportMembers(f.ctxA, importActor, f.gymA.ID, rows, false, allowAll)
+			if err != nil {
+				t.Fatalf("повторный ImportMembers: %v", err)
+			}
+			if reports[0].Result != models.ImportUpdated {
+				t.Errorf("участник зала: ожидался результат %s, получен %s (%s)", models.ImportUpdated, reports[0].Result, reports[0].Error)
+			}
+		})
+	}
+}
+
+func TestImportKeepsSharedUserProfile(t *testing.T) {
+	allowAll := func(from, to models.ActivityStatus) error { return nil }
+
+	for _, rls := range []bool{false, true} {
+		t.Run(fmt.Sprintf("rls=%t", rls), func(t *testing.T) {
+			f := newTenantFixture(t)
+			repo := f.repo
+			if rls {
+				repo = NewRepository(f.db, nil)
+				repo.EnableRowLevelSecurity(rlsTestRole)
+			}
+
+			// Пользователь B состоит и в зале A, но его учетная запись общая с организацией B
+			if err := f.repo.AddUserToGym(f.ctxA, memberActor(f.userB), f.userB, f.gymA.ID, models.ActiveStatus); err != nil {
+				t.Fatalf("добавление пользователя B в зал A: %v", err)
+			}
+
+			rows := []models.ImportMemberRow{
+				{ID: f.userB, Email: "shared-" + f.suffix + "@example.com", FirstName: "Shared", LastName: "User", Status: models.ActiveStatus},
+			}
+			reports, err := repo.ImportMembers(f.ctxA, importActor, f.gymA.ID, rows, false, allowAll)
+			if err != nil {
+				t.Fatalf("ImportMembers: %v", err)
+			}
+			if reports[0].Result != models.ImportFailed {
+				t.Errorf("общий пользователь: ожидался результат %s, получен %s", models.ImportFailed, reports[0].Result)
+			}
+
+			var email string
+			if err := f.db.Get(&email, `SELECT email FROM users WHERE id = $1`, f.userB); err != nil {
+				t.Fatalf("чтение пользователя B: %v", err)
+			}
+			if email != f.emailB {
+				t.Errorf("импорт организации A изменил email общего пользователя: %s", email)
+			}
+		})
+	}
+}
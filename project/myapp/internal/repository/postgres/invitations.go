@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"myapp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const invitationColumns = `id, gym_id, code, email, max_uses, uses, expires_at, revoked_at, created_by,
+	created_by_role, created_at`
+
+// GetGymSettings получает настройки зала. Для зала без настроек возвращаются значения по умолчанию.
+func (r *Repository) GetGymSettings(ctx context.Context, gymID string) (models.GymSettings, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.GymSettings{}, err
+	}
+
+	query := `
+		SELECT gym_id, join_policy, streak_min_visits, updated_at
+		FROM gym_settings
+		WHERE gym_id = $1
+	`
+
+	var settings models.GymSettings
+	err := r.db.GetContext(ctx, &settings, query, gymID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.GymSettings{
+			GymID:           gymID,
+			JoinPolicy:      models.OpenPolicy,
+			StreakMinVisits: models.DefaultStreakMinVisits,
+		}, nil
+	}
+
+	return settings, err
+}
+
+// UpdateGymSettings сохраняет настройки зала
+func (r *Repository) UpdateGymSettings(ctx context.Context, settings models.GymSettings) (models.GymSettings, error) {
+	if err := checkGym(ctx, r.db, settings.GymID); err != nil {
+		return models.GymSettings{}, err
+	}
+
+	query := `
+		INSERT INTO gym_settings (gym_id, join_policy, streak_min_visits, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (gym_id) DO UPDATE
+		SET join_policy = EXCLUDED.join_policy, streak_min_visits = EXCLUDED.streak_min_visits,
+			updated_at = EXCLUDED.updated_at
+		RETURNING gym_id, join_policy, streak_min_visits, updated_at
+	`
+
+	var updated models.GymSettings
+	err := r.db.GetContext(ctx, &updated, query,
+		settings.GymID, settings.JoinPolicy, settings.StreakMinVisits, utcNow())
+	return updated, err
+}
+
+// CreateInvitation сохраняет новое приглашение
+func (r *Repository) CreateInvitation(ctx context.Context, invitation models.Invitation) (models.Invitation, error) {
+	if err := checkGym(ctx, r.db, invitation.GymID); err != nil {
+		return models.Invitation{}, err
+	}
+
+	query := `
+		INSERT INTO invitations (gym_id, code, email, max_uses, expires_at, created_by, created_by_role, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + invitationColumns
+
+	var created models.Invitation
+	err := r.db.GetContext(ctx, &created, query,
+		invitation.GymID, invitation.Code, invitation.Email, invitation.MaxUses,
+		invitation.ExpiresAt, invitation.CreatedBy, invitation.CreatedByRole, utcNow())
+	if isUniqueViolation(err) {
+		return models.Invitation{}, fmt.Errorf("%w: код приглашения уже существует", models.ErrConflict)
+	}
+
+	return created, err
+}
+
+// ListInvitations получает приглашения зала
+func (r *Repository) ListInvitations(ctx context.Context, gymID string) ([]models.Invitation, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE gym_id = $1 ORDER BY created_at DESC`
+
+	invitations := []models.Invitation{}
+	if err := r.db.SelectContext(ctx, &invitations, query, gymID); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation отзывает приглашение зала
+func (r *Repository) RevokeInvitation(ctx context.Context, gymID, invitationID string) (models.Invitation, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.Invitation{}, err
+	}
+
+	query := `
+		UPDATE invitations
+		SET revoked_at = COALESCE(revoked_at, $1)
+		WHERE gym_id = $2 AND id = $3
+		RETURNING ` + invitationColumns
+
+	var invitation models.Invitation
+	if err := r.db.GetContext(ctx, &invitation, query, utcNow(), gymID, invitationID); err != nil {
+		return models.Invitation{}, notFound(err, "приглашение не найдено")
+	}
+
+	return invitation, nil
+}
+
+// RedeemInvitation погашает приглашение и добавляет пользователя actor в зал в одной транзакции.
+// Приглашение блокируется, поэтому одновременные погашения не превысят лимит использований.
+// check получает приглашение и email пользователя и решает, можно ли его погасить.
+func (r *Repository) RedeemInvitation(ctx context.Context, actor models.Actor, code string, check func(models.Invitation, string) error) (models.Invitation, error) {
+	userID := actor.UserID
+	var invitation models.Invitation
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `SELECT ` + invitationColumns + ` FROM invitations WHERE code = $1 FOR UPDATE`
+		if err := tx.GetContext(ctx, &invitation, query, code); err != nil {
+			return notFound(err, "приглашение не найдено")
+		}
+		// Приглашение в зал другой организации неотличимо от несуществующего
+		if err := checkGym(ctx, tx, invitation.GymID); errors.Is(err, models.ErrNotFound) {
+			return fmt.Errorf("%w: приглашение не найдено", models.ErrNotFound)
+		} else if err != nil {
+			return err
+		}
+
+		var email string
+		if err := tx.GetContext(ctx, &email, `SELECT email FROM users WHERE id = $1`, userID); err != nil {
+			return notFound(err, "пользователь не найден")
+		}
+
+		if err := check(invitation, email); err != nil {
+			return err
+		}
+
+		now := utcNow()
+		change := models.StatusChange{Status: models.ActiveStatus, ChangedBy: userID, ChangedByRole: actor.Role}
+		joined, err := r.joinMember(ctx, tx, userID, invitation.GymID, change, now)
+		if err != nil {
+			return err
+		}
+		if !joined {
+			return fmt.Errorf("%w: пользователь уже связан с этим залом", models.ErrConflict)
+		}
+
+		query = `
+			INSERT INTO invitation_redemptions (invitation_id, user_id, redeemed_at)
+			VALUES ($1, $2, $3)
+		`
+		if _, err := tx.ExecContext(ctx, query, invitation.ID, userID, now); err != nil {
+			return err
+		}
+
+		query = `UPDATE invitations SET uses = uses + 1 WHERE id = $1 RETURNING uses`
+		return tx.GetContext(ctx, &invitation.Uses, query, invitation.ID)
+	})
+	if err != nil {
+		return models.Invitation{}, err
+	}
+
+	return invitation, nil
+}
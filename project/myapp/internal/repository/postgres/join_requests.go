@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// ListJoinRequests получает заявки зала, ожидающие одобрения
+func (r *Repository) ListJoinRequests(ctx context.Context, gymID string) ([]models.JoinRequest, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT gm.user_id, u.email, u.first_name, u.last_name, gm.status, gm.joined_at
+		FROM group_members gm
+		JOIN users u ON u.id = gm.user_id
+		WHERE gm.gym_id = $1 AND gm.status = $2
+		ORDER BY gm.joined_at
+	`
+
+	requests := []models.JoinRequest{}
+	if err := r.db.SelectContext(ctx, &requests, query, gymID, models.PendingStatus); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
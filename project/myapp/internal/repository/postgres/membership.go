@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"myapp/internal/events"
+	"myapp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// changeStatus переводит заблокированную запись участника из previous в новый статус,
+// дописывает историю статусов и записывает событие в outbox.
+// Возвращает новую версию записи участника.
+func (r *Repository) changeStatus(ctx context.Context, tx *sqlx.Tx, userID, gymID string, previous models.ActivityStatus, change models.StatusChange, now time.Time) (int64, error) {
+	query := `
+		UPDATE group_members
+		SET status = $1, status_reason = NULLIF($2, ''), status_changed_by = NULLIF($3, ''),
+			status_changed_at = $4, updated_at = $4
+		WHERE user_id = $5 AND gym_id = $6
+		RETURNING version
+	`
+	var version int64
+	if err := tx.GetContext(ctx, &version, query, change.Status, change.Reason, change.ChangedBy, now, userID, gymID); err != nil {
+		return 0, err
+	}
+
+	if err := insertStatusHistory(ctx, tx, userID, gymID, previous, change, now); err != nil {
+		return 0, err
+	}
+
+	eventType := events.MemberStatusChanged
+	if change.Status == models.LeftStatus {
+		eventType = events.MemberLeft
+	}
+
+	return version, r.recordEvent(ctx, tx, eventType, events.MemberPayload{
+		UserID:         userID,
+		GymID:          gymID,
+		Status:         change.Status,
+		PreviousStatus: previous,
+		Reason:         change.Reason,
+		OccurredAt:     now,
+	})
+}
+
+// joinMember добавляет пользователя в зал или возвращает в него покинувшего зал участника.
+// Возвращает false, если пользователь уже связан с залом в другом статусе.
+func (r *Repository) joinMember(ctx context.Context, tx *sqlx.Tx, userID, gymID string, change models.StatusChange, now time.Time) (bool, error) {
+	query := `
+		INSERT INTO group_members (user_id, gym_id, status, status_changed_by, status_changed_at, joined_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $5, $5)
+		ON CONFLICT (user_id, gym_id) DO UPDATE
+		SET status = EXCLUDED.status, status_reason = NULL, status_changed_by = EXCLUDED.status_changed_by,
+			status_changed_at = EXCLUDED.status_changed_at, joined_at = EXCLUDED.joined_at,
+			updated_at = EXCLUDED.updated_at
+		WHERE group_members.status = $6
+		RETURNING (xmax = 0) AS inserted
+	`
+
+	var inserted bool
+	err := tx.GetContext(ctx, &inserted, query, userID, gymID, change.Status, change.ChangedBy, now, models.LeftStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if isForeignKeyViolation(err, "group_members_gym_id_fkey") {
+		return false, fmt.Errorf("%w: зал не найден", models.ErrNotFound)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var previous models.ActivityStatus
+	if !inserted {
+		previous = models.LeftStatus
+	}
+
+	if err := insertStatusHistory(ctx, tx, userID, gymID, previous, change, now); err != nil {
+		return false, err
+	}
+
+	return true, r.recordEvent(ctx, tx, events.MemberJoined, events.MemberPayload{
+		UserID:         userID,
+		GymID:          gymID,
+		Status:         change.Status,
+		PreviousStatus: previous,
+		OccurredAt:     now,
+	})
+}
+
+// insertStatusHistory дописывает переход статуса в историю участника.
+// Пустой previous означает первое вступление в зал.
+func insertStatusHistory(ctx context.Context, tx *sqlx.Tx, userID, gymID string, previous models.ActivityStatus, change models.StatusChange, now time.Time) error {
+	query := `
+		INSERT INTO member_status_history (user_id, gym_id, from_status, to_status, reason, changed_by, changed_by_role, changed_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8)
+	`
+	_, err := tx.ExecContext(ctx, query, userID, gymID, previous, change.Status, change.Reason,
+		change.ChangedBy, change.ChangedByRole, now)
+	return err
+}
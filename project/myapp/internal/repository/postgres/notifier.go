@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"myapp/internal/events"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Notifier отправляет уведомления об изменениях через NOTIFY
+type Notifier struct {
+	channel string
+}
+
+// NewNotifier создает новый источник уведомлений для канала channel
+func NewNotifier(channel string) *Notifier {
+	return &Notifier{
+		channel: channel,
+	}
+}
+
+// Notify отправляет событие в канал в рамках транзакции.
+// Postgres доставит уведомление только после фиксации транзакции.
+func (n *Notifier) Notify(ctx context.Context, tx sqlx.ExecerContext, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, n.channel, string(payload))
+	return err
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+const organizationColumns = `id, name, created_at, updated_at`
+
+// CreateOrganization сохраняет новую организацию
+func (r *Repository) CreateOrganization(ctx context.Context, org models.Organization) (models.Organization, error) {
+	query := `
+		INSERT INTO organizations (name, created_at, updated_at)
+		VALUES ($1, $2, $2)
+		RETURNING ` + organizationColumns
+
+	var created models.Organization
+	err := r.db.GetContext(ctx, &created, query, org.Name, utcNow())
+	return created, err
+}
+
+// ListOrganizations получает организации. Контекст, ограниченный организацией,
+// видит только ее.
+func (r *Repository) ListOrganizations(ctx context.Context) ([]models.Organization, error) {
+	query := `
+		SELECT ` + organizationColumns + `
+		FROM organizations
+		WHERE $1 = '*' OR id::text = $1
+		ORDER BY name, id
+	`
+
+	orgs := []models.Organization{}
+	if err := r.db.SelectContext(ctx, &orgs, query, tenantFilter(ctx)); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"myapp/internal/events"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// outboxLockKey - ключ advisory-блокировки, под которой реплика захватывает пачку outbox
+const outboxLockKey = 727001
+
+// OutboxRepository хранит доменные события в таблице outbox
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewOutboxRepository создает новый репозиторий outbox
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// insertEvent записывает событие участника в outbox в рамках транзакции
+func insertEvent(ctx context.Context, tx *sqlx.Tx, eventType events.Type, payload events.MemberPayload) (events.Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return events.Event{}, err
+	}
+
+	event := events.Event{
+		Type:         eventType,
+		AggregateKey: events.MemberKey(payload.UserID, payload.GymID),
+		Payload:      data,
+		CreatedAt:    payload.OccurredAt,
+	}
+
+	query := `
+		INSERT INTO outbox (event_type, aggregate_key, payload, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	err = tx.GetContext(ctx, &event.ID, query, event.Type, event.AggregateKey, event.Payload, event.CreatedAt)
+	return event, err
+}
+
+// ClaimPending захватывает неопубликованные события на время lease и возвращает их
+// в порядке записи. Захват выполняется под транзакционной advisory-блокировкой, поэтому
+// реплики не захватывают пачки одновременно. Событие не захватывается, пока более раннее
+// событие того же участника захвачено другой репликой: события участника публикуются
+// в порядке записи.
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]events.Event, error) {
+	var batch []events.Event
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, outboxLockKey); err != nil {
+			return err
+		}
+
+		now := utcNow()
+		query := `
+			UPDATE outbox
+			SET locked_until = $1
+			WHERE id IN (
+				SELECT o.id
+				FROM outbox o
+				WHERE o.published_at IS NULL
+					AND (o.locked_until IS NULL OR o.locked_until <= $2)
+					AND NOT EXISTS (
+						SELECT 1 FROM outbox e
+						WHERE e.aggregate_key = o.aggregate_key AND e.id < o.id
+							AND e.published_at IS NULL AND e.locked_until > $2
+					)
+				ORDER BY o.id
+				LIMIT $3
+			)
+			RETURNING id, event_type, aggregate_key, payload, created_at
+		`
+		return tx.SelectContext(ctx, &batch, query, now.Add(lease), now, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(batch, func(i, j int) bool { return batch[i].ID < batch[j].ID })
+	return batch, nil
+}
+
+// CompleteBatch отмечает опубликованные события, сохраняет ошибки публикации
+// и снимает захват с событий пачки, которые не удалось опубликовать
+func (r *OutboxRepository) CompleteBatch(ctx context.Context, batch []events.Event, published []int64, failed map[int64]error) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if len(published) > 0 {
+			query := `
+				UPDATE outbox SET published_at = $1, attempts = attempts + 1, locked_until = NULL
+				WHERE id = ANY($2)
+			`
+			if _, err := tx.ExecContext(ctx, query, utcNow(), pq.Array(published)); err != nil {
+				return err
+			}
+		}
+
+		for id, publishErr := range failed {
+			query := `UPDATE outbox SET attempts = attempts + 1, last_error = $1, locked_until = NULL WHERE id = $2`
+			if _, err := tx.ExecContext(ctx, query, publishErr.Error(), id); err != nil {
+				return err
+			}
+		}
+
+		// События, пропущенные после ошибки по тому же участнику
+		ids := make([]int64, len(batch))
+		for i, event := range batch {
+			ids[i] = event.ID
+		}
+		query := `UPDATE outbox SET locked_until = NULL WHERE id = ANY($1) AND published_at IS NULL`
+		_, err := tx.ExecContext(ctx, query, pq.Array(ids))
+		return err
+	})
+}
+
+// DeletePublished удаляет события, опубликованные раньше указанного момента
+func (r *OutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM outbox WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"fmt"
+
+	"myapp/internal/models"
+)
+
+// keysetClause дописывает к запросу условие и порядок выборки страницы по паре
+// столбцов (timeColumn, idColumn). descending - порядок самого списка; страница
+// назад выбирается в обратном порядке. Выбирается на одну запись больше лимита,
+// чтобы узнать, есть ли следующая страница в направлении выборки.
+func keysetClause(page models.PageQuery, timeColumn, idColumn string, descending bool, args []interface{}) (string, []interface{}) {
+	operator, direction := ">", "ASC"
+	if descending != page.Backward {
+		operator, direction = "<", "DESC"
+	}
+
+	clause := ""
+	if page.After != nil {
+		args = append(args, page.After.Time, page.After.ID)
+		clause = fmt.Sprintf(" AND (%s, %s) %s ($%d, $%d)", timeColumn, idColumn, operator, len(args)-1, len(args))
+	}
+	clause += fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT %d", timeColumn, direction, idColumn, direction, page.Limit+1)
+
+	return clause, args
+}
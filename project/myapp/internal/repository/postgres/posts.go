@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"myapp/internal/models"
+)
+
+// postColumns - столбцы публикации вместе с именем автора
+const postColumns = `
+	p.id, p.gym_id, p.author_id, u.first_name AS author_first_name, u.last_name AS author_last_name,
+	p.text, p.pinned, p.created_at, p.updated_at
+`
+
+// CreatePost сохраняет новую публикацию в ленте группы
+func (r *Repository) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	if err := checkGym(ctx, r.db, post.GymID); err != nil {
+		return models.Post{}, err
+	}
+
+	query := `
+		WITH p AS (
+			INSERT INTO posts (gym_id, author_id, text, pinned, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+			RETURNING *
+		)
+		SELECT ` + postColumns + `
+		FROM p
+		JOIN users u ON u.id = p.author_id
+	`
+
+	var created models.Post
+	err := r.db.GetContext(ctx, &created, query, post.GymID, post.AuthorID, post.Text, post.Pinned, utcNow())
+	if isForeignKeyViolation(err, "posts_author_id_fkey") {
+		return models.Post{}, fmt.Errorf("%w: у автора нет учетной записи пользователя", models.ErrForbidden)
+	}
+	if err != nil {
+		return models.Post{}, notFound(err, "автор не найден")
+	}
+
+	return created, nil
+}
+
+// GetPost получает публикацию группы по ID
+func (r *Repository) GetPost(ctx context.Context, gymID, postID string) (models.Post, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.Post{}, err
+	}
+
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		JOIN users u ON u.id = p.author_id
+		WHERE p.gym_id = $1 AND p.id = $2
+	`
+
+	var post models.Post
+	if err := r.db.GetContext(ctx, &post, query, gymID, postID); err != nil {
+		return models.Post{}, notFound(err, "публикация не найдена")
+	}
+
+	return post, nil
+}
+
+// UpdatePost сохраняет текст и признак закрепления публикации
+func (r *Repository) UpdatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	if err := checkGym(ctx, r.db, post.GymID); err != nil {
+		return models.Post{}, err
+	}
+
+	query := `
+		WITH p AS (
+			UPDATE posts
+			SET text = $1, pinned = $2, updated_at = $3
+			WHERE gym_id = $4 AND id = $5
+			RETURNING *
+		)
+		SELECT ` + postColumns + `
+		FROM p
+		JOIN users u ON u.id = p.author_id
+	`
+
+	var updated models.Post
+	err := r.db.GetContext(ctx, &updated, query, post.Text, post.Pinned, utcNow(), post.GymID, post.ID)
+	if err != nil {
+		return models.Post{}, notFound(err, "публикация не найдена")
+	}
+
+	return updated, nil
+}
+
+// DeletePost удаляет публикацию группы
+func (r *Repository) DeletePost(ctx context.Context, gymID, postID string) error {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM posts WHERE gym_id = $1 AND id = $2`, gymID, postID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return notFound(sql.ErrNoRows, "публикация не найдена")
+	}
+
+	return nil
+}
+
+// ListPosts получает страницу публикаций группы, упорядоченных от новых к старым.
+// Записи возвращаются в направлении выборки, см. keysetClause.
+func (r *Repository) ListPosts(ctx context.Context, gymID string, page models.PageQuery) ([]models.Post, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		JOIN users u ON u.id = p.author_id
+		WHERE p.gym_id = $1
+	`
+	clause, args := keysetClause(page, "p.created_at", "p.id", true, []interface{}{gymID})
+	query += clause
+
+	posts := []models.Post{}
+	if err := r.db.SelectContext(ctx, &posts, query, args...); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// ListPinnedPosts получает закрепленные публикации группы
+func (r *Repository) ListPinnedPosts(ctx context.Context, gymID string) ([]models.Post, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		JOIN users u ON u.id = p.author_id
+		WHERE p.gym_id = $1 AND p.pinned
+		ORDER BY p.created_at DESC
+	`
+
+	posts := []models.Post{}
+	if err := r.db.SelectContext(ctx, &posts, query, gymID); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// CountUnreadPosts считает чужие публикации группы, появившиеся после последнего прочтения ленты
+func (r *Repository) CountUnreadPosts(ctx context.Context, gymID, userID string) (int, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return 0, err
+	}
+
+	query := `
+		SELECT COUNT(*)
+		FROM posts p
+		LEFT JOIN post_reads pr ON pr.gym_id = p.gym_id AND pr.user_id = $2
+		WHERE p.gym_id = $1 AND p.author_id <> $2
+			AND (pr.last_read_at IS NULL OR p.created_at > pr.last_read_at)
+	`
+
+	var unread int
+	if err := r.db.GetContext(ctx, &unread, query, gymID, userID); err != nil {
+		return 0, err
+	}
+
+	return unread, nil
+}
+
+// MarkPostsRead отмечает ленту группы прочитанной до момента readAt.
+// Отметка не сдвигается назад, если более поздняя уже сохранена.
+func (r *Repository) MarkPostsRead(ctx context.Context, gymID, userID string, readAt time.Time) error {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO post_reads (user_id, gym_id, last_read_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, gym_id) DO UPDATE
+		SET last_read_at = GREATEST(post_reads.last_read_at, EXCLUDED.last_read_at)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID, gymID, readAt)
+	return err
+}
@@ -0,0 +1,317 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"myapp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// erasableStatuses - статусы, из которых пользователь выходит из зала при удалении данных.
+// Блокировка и отклонение заявки сохраняются, чтобы удаление не снимало их.
+var erasableStatuses = pq.StringArray{
+	string(models.PendingStatus),
+	string(models.ActiveStatus),
+	string(models.InactiveStatus),
+	string(models.SuspendedStatus),
+}
+
+// ExportUserData собирает все данные пользователя: профиль, участие в залах, историю
+// статусов, посещения, статистику и награды, публикации и отметки о прочтении, записи
+// на занятия, настройки подбора партнеров и вступления по приглашениям.
+// Данные читаются в одной транзакции.
+func (r *Repository) ExportUserData(ctx context.Context, userID string) (models.DataExport, error) {
+	export := models.DataExport{
+		Memberships:           []models.GroupMember{},
+		StatusHistory:         []models.StatusHistoryEntry{},
+		Visits:                []models.Visit{},
+		Stats:                 []models.MemberStats{},
+		Posts:                 []models.Post{},
+		PostReads:             []models.PostRead{},
+		Bookings:              []models.Booking{},
+		BuddyPreferences:      []models.GymBuddyPreferences{},
+		InvitationRedemptions: []models.InvitationRedemption{},
+	}
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			SELECT id, email, first_name, last_name, created_at, updated_at
+			FROM users
+			WHERE id = $1
+		`
+		if err := tx.GetContext(ctx, &export.User, query, userID); err != nil {
+			return notFound(err, "пользователь не найден")
+		}
+
+		query = `
+			SELECT id, user_id, gym_id, status, joined_at, updated_at
+			FROM group_members
+			WHERE user_id = $1
+			ORDER BY joined_at, gym_id
+		`
+		if err := tx.SelectContext(ctx, &export.Memberships, query, userID); err != nil {
+			return err
+		}
+
+		query = `
+			SELECT id, user_id, gym_id, from_status, to_status, reason, changed_by, changed_by_role, changed_at
+			FROM member_status_history
+			WHERE user_id = $1
+			ORDER BY changed_at, id
+		`
+		if err := tx.SelectContext(ctx, &export.StatusHistory, query, userID); err != nil {
+			return err
+		}
+
+		query = `
+			SELECT id, user_id, gym_id, visited_at
+			FROM visits
+			WHERE user_id = $1
+			ORDER BY visited_at, id
+		`
+		if err := tx.SelectContext(ctx, &export.Visits, query, userID); err != nil {
+			return err
+		}
+
+		if err := exportStats(ctx, tx, userID, &export); err != nil {
+			return err
+		}
+
+		query = `
+			SELECT p.id, p.gym_id, p.author_id, u.first_name AS author_first_name, u.last_name AS author_last_name,
+				p.text, p.pinned, p.created_at, p.updated_at
+			FROM posts p
+			JOIN users u ON u.id = p.author_id
+			WHERE p.author_id = $1
+			ORDER BY p.created_at, p.id
+		`
+		if err := tx.SelectContext(ctx, &export.Posts, query, userID); err != nil {
+			return err
+		}
+
+		query = `SELECT gym_id, last_read_at FROM post_reads WHERE user_id = $1 ORDER BY gym_id`
+		if err := tx.SelectContext(ctx, &export.PostReads, query, userID); err != nil {
+			return err
+		}
+
+		query = `
+			SELECT b.id, s.class_id, b.user_id, s.starts_at, b.status, b.created_at
+			FROM class_bookings b
+			JOIN class_sessions s ON s.id = b.session_id
+			WHERE b.user_id = $1
+			ORDER BY s.starts_at, b.id
+		`
+		if err := tx.SelectContext(ctx, &export.Bookings, query, userID); err != nil {
+			return err
+		}
+
+		query = `
+			SELECT gym_id, opted_in, hidden, updated_at
+			FROM buddy_preferences
+			WHERE user_id = $1
+			ORDER BY gym_id
+		`
+		if err := tx.SelectContext(ctx, &export.BuddyPreferences, query, userID); err != nil {
+			return err
+		}
+
+		// Код приглашения не выгружается: это секрет зала, а не данные пользователя
+		query = `
+			SELECT ir.invitation_id, i.gym_id, ir.redeemed_at
+			FROM invitation_redemptions ir
+			JOIN invitations i ON i.id = ir.invitation_id
+			WHERE ir.user_id = $1
+			ORDER BY ir.redeemed_at, ir.invitation_id
+		`
+		return tx.SelectContext(ctx, &export.InvitationRedemptions, query, userID)
+	})
+	if err != nil {
+		return models.DataExport{}, err
+	}
+
+	export.ExportedAt = utcNow()
+	return export, nil
+}
+
+// exportStats добавляет в выгрузку статистику пользователя по залам вместе с наградами
+func exportStats(ctx context.Context, tx *sqlx.Tx, userID string, export *models.DataExport) error {
+	query := `
+		SELECT user_id, gym_id, total_visits, current_streak, longest_streak, streak_week
+		FROM member_stats
+		WHERE user_id = $1
+		ORDER BY gym_id
+	`
+	if err := tx.SelectContext(ctx, &export.Stats, query, userID); err != nil {
+		return err
+	}
+
+	// Награды выдаются по сводке member_stats, поэтому у каждой награды есть сводка зала
+	var badges []struct {
+		GymID string `db:"gym_id"`
+		models.Badge
+	}
+	query = `
+		SELECT mb.gym_id, mb.user_id, br.code, br.title, mb.awarded_at
+		FROM member_badges mb
+		JOIN badge_rules br ON br.id = mb.rule_id
+		WHERE mb.user_id = $1
+		ORDER BY mb.awarded_at, br.code
+	`
+	if err := tx.SelectContext(ctx, &badges, query, userID); err != nil {
+		return err
+	}
+
+	for i := range export.Stats {
+		export.Stats[i].Badges = []models.Badge{}
+		for _, b := range badges {
+			if b.GymID == export.Stats[i].GymID {
+				export.Stats[i].Badges = append(export.Stats[i].Badges, b.Badge)
+			}
+		}
+	}
+	return nil
+}
+
+// EraseUser обезличивает пользователя и удаляет его персональные данные в одной транзакции,
+// сохраняя посещения, серии и награды для общей статистики: пользователь выходит из залов,
+// причины смены статусов стираются, публикации, отметки о прочтении и настройки подбора
+// партнеров удаляются, будущие записи на занятия отменяются. Удаление записывается
+// в журнал user_erasures.
+//
+// Если в erasure задана организация, удаляются только данные в ее залах, а учетная
+// запись пользователя, общая для всех организаций, не меняется.
+func (r *Repository) EraseUser(ctx context.Context, erasure models.Erasure) (models.Erasure, error) {
+	var recorded models.Erasure
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var user struct {
+			Email    string     `db:"email"`
+			ErasedAt *time.Time `db:"erased_at"`
+		}
+		query := `SELECT email, erased_at FROM users WHERE id = $1 FOR UPDATE`
+		if err := tx.GetContext(ctx, &user, query, erasure.UserID); err != nil {
+			return notFound(err, "пользователь не найден")
+		}
+		if user.ErasedAt != nil {
+			return fmt.Errorf("%w: данные пользователя уже удалены", models.ErrConflict)
+		}
+
+		now := utcNow()
+		var memberships []models.GroupMember
+		query = `
+			SELECT id, user_id, gym_id, status, joined_at, updated_at
+			FROM group_members
+			WHERE user_id = $1 AND status = ANY($2) AND ` + inOrganization("gym_id", 3) + `
+			ORDER BY gym_id
+			FOR UPDATE
+		`
+		if err := tx.SelectContext(ctx, &memberships, query, erasure.UserID, erasableStatuses, erasure.OrganizationID); err != nil {
+			return err
+		}
+		erasure.GymIDs = pq.StringArray{}
+		for _, m := range memberships {
+			change := models.StatusChange{Status: models.LeftStatus, ChangedBy: erasure.ErasedBy, ChangedByRole: erasure.ActorRole}
+			if _, err := r.changeStatus(ctx, tx, m.UserID, m.GymID, m.Status, change, now); err != nil {
+				return err
+			}
+			erasure.GymIDs = append(erasure.GymIDs, m.GymID)
+		}
+
+		if err := cancelFutureBookings(ctx, tx, erasure.UserID, erasure.OrganizationID, now); err != nil {
+			return err
+		}
+
+		statements := []string{
+			`UPDATE group_members SET status_reason = NULL WHERE user_id = $1 AND ` + inOrganization("gym_id", 2),
+			`UPDATE member_status_history SET reason = NULL WHERE user_id = $1 AND ` + inOrganization("gym_id", 2),
+			`DELETE FROM posts WHERE author_id = $1 AND ` + inOrganization("gym_id", 2),
+			`DELETE FROM post_reads WHERE user_id = $1 AND ` + inOrganization("gym_id", 2),
+			`DELETE FROM buddy_preferences WHERE user_id = $1 AND ` + inOrganization("gym_id", 2),
+		}
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement, erasure.UserID, erasure.OrganizationID); err != nil {
+				return err
+			}
+		}
+
+		// Приглашения на email пользователя отзываются, чтобы адрес не остался в базе
+		query = `
+			UPDATE invitations
+			SET email = NULL, revoked_at = COALESCE(revoked_at, $2)
+			WHERE lower(email) = lower($1) AND ` + inOrganization("gym_id", 3)
+		if _, err := tx.ExecContext(ctx, query, user.Email, now, erasure.OrganizationID); err != nil {
+			return err
+		}
+
+		if erasure.OrganizationID == nil {
+			query = `
+				UPDATE users
+				SET email = 'erased+' || id::text || '@invalid', first_name = '', last_name = '',
+					erased_at = $2, updated_at = $2
+				WHERE id = $1
+			`
+			if _, err := tx.ExecContext(ctx, query, erasure.UserID, now); err != nil {
+				return err
+			}
+		}
+
+		query = `
+			INSERT INTO user_erasures (user_id, erased_by, actor_role, organization_id, reason, gym_ids, erased_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, user_id, erased_by, actor_role, organization_id, reason, gym_ids, erased_at
+		`
+		return tx.GetContext(ctx, &recorded, query, erasure.UserID, erasure.ErasedBy, erasure.ActorRole,
+			erasure.OrganizationID, erasure.Reason, erasure.GymIDs, now)
+	})
+	if err != nil {
+		return models.Erasure{}, err
+	}
+
+	return recorded, nil
+}
+
+// inOrganization возвращает условие принадлежности зала в столбце column организации
+// из параметра запроса с номером param. Пустой параметр (NULL) означает любую организацию.
+func inOrganization(column string, param int) string {
+	return fmt.Sprintf("($%[2]d::uuid IS NULL OR %[1]s IN (SELECT id FROM gyms WHERE organization_id = $%[2]d::uuid))", column, param)
+}
+
+// cancelFutureBookings отменяет записи пользователя на будущие занятия в залах организации
+// orgID (во всех залах, если orgID пуст) и отдает освободившиеся места участникам из листа ожидания
+func cancelFutureBookings(ctx context.Context, tx *sqlx.Tx, userID string, orgID *string, now time.Time) error {
+	var sessions []struct {
+		ID       string `db:"id"`
+		Capacity int    `db:"capacity"`
+	}
+	query := `
+		SELECT s.id, c.capacity
+		FROM class_bookings b
+		JOIN class_sessions s ON s.id = b.session_id
+		JOIN classes c ON c.id = s.class_id
+		WHERE b.user_id = $1 AND b.status <> $2 AND s.starts_at > $3 AND ` + inOrganization("c.gym_id", 4) + `
+		ORDER BY s.id
+		FOR UPDATE OF s
+	`
+	if err := tx.SelectContext(ctx, &sessions, query, userID, models.BookingCancelled, now, orgID); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		query := `
+			UPDATE class_bookings
+			SET status = $1, updated_at = $2
+			WHERE session_id = $3 AND user_id = $4 AND status <> $1
+		`
+		if _, err := tx.ExecContext(ctx, query, models.BookingCancelled, now, session.ID, userID); err != nil {
+			return err
+		}
+		if err := promoteWaitlist(ctx, tx, session.ID, session.Capacity, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,38 +2,49 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
+	"myapp/internal/events"
 	"myapp/internal/models"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Repository взаимодействует с базой данных для операций с группами
 type Repository struct {
-	db *sqlx.DB
+	db       *DB
+	notifier *Notifier
 }
 
-// NewRepository создает новый экземпляр репозитория
-func NewRepository(db *sqlx.DB) *Repository {
+// NewRepository создает новый экземпляр репозитория.
+// Если notifier не nil, об изменениях участников сообщается через NOTIFY.
+func NewRepository(db *sqlx.DB, notifier *Notifier) *Repository {
 	return &Repository{
-		db: db,
+		db:       &DB{db: db},
+		notifier: notifier,
 	}
 }
 
 // GetGroupMembers получает всех участников зала
 func (r *Repository) GetGroupMembers(ctx context.Context, gymID string) ([]models.User, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
 	var users []models.User
 
 	query := `
 		SELECT u.id, u.email, u.first_name, u.last_name, gm.status, u.created_at, u.updated_at
 		FROM users u
 		JOIN group_members gm ON u.id = gm.user_id
-		WHERE gm.gym_id = $1
+		WHERE gm.gym_id = $1 AND gm.status = ANY($2)
 	`
 
-	err := r.db.SelectContext(ctx, &users, query, gymID)
+	err := r.db.SelectContext(ctx, &users, query, gymID, memberStatuses())
 	if err != nil {
 		return nil, err
 	}
@@ -41,27 +52,64 @@ func (r *Repository) GetGroupMembers(ctx context.Context, gymID string) ([]model
 	return users, nil
 }
 
-// GetUserGroup получает группу, к которой принадлежит пользователь
-func (r *Repository) GetUserGroup(ctx context.Context, userID string) (models.Group, []models.User, error) {
-	var group models.Group
-	var users []models.User
+// ListGroupMembersPage получает страницу участников зала в порядке вступления.
+// Записи возвращаются в направлении выборки, см. keysetClause.
+func (r *Repository) ListGroupMembersPage(ctx context.Context, gymID string, page models.PageQuery) ([]models.MemberListEntry, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
 
-	// Сначала получаем зал, к которому принадлежит пользователь
-	queryGym := `
-		SELECT g.id, g.gym_id, g.name, g.created_at
-		FROM groups g
-		JOIN group_members gm ON g.gym_id = gm.gym_id
-		WHERE gm.user_id = $1
-		LIMIT 1
+	query := `
+		SELECT u.id, u.email, u.first_name, u.last_name, gm.status, u.created_at, u.updated_at,
+			gm.joined_at, gm.id AS membership_id
+		FROM users u
+		JOIN group_members gm ON u.id = gm.user_id
+		WHERE gm.gym_id = $1 AND gm.status = ANY($2)
 	`
+	clause, args := keysetClause(page, "gm.joined_at", "gm.id", false, []interface{}{gymID, memberStatuses()})
+	query += clause
+
+	members := []models.MemberListEntry{}
+	if err := r.db.SelectContext(ctx, &members, query, args...); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// ListStatusHistory получает страницу истории статусов участника зала от новых записей к старым.
+// Записи возвращаются в направлении выборки, см. keysetClause.
+func (r *Repository) ListStatusHistory(ctx context.Context, gymID, userID string, page models.PageQuery) ([]models.StatusHistoryEntry, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, user_id, gym_id, from_status, to_status, reason, changed_by, changed_by_role, changed_at
+		FROM member_status_history
+		WHERE gym_id = $1 AND user_id = $2
+	`
+	clause, args := keysetClause(page, "changed_at", "id", true, []interface{}{gymID, userID})
+	query += clause
+
+	entries := []models.StatusHistoryEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, err
+	}
 
-	err := r.db.GetContext(ctx, &group, queryGym, userID)
+	return entries, nil
+}
+
+// GetUserGroup получает группу, к которой принадлежит пользователь
+func (r *Repository) GetUserGroup(ctx context.Context, userID string) (models.Group, []models.User, error) {
+	// Сначала получаем зал, к которому принадлежит пользователь
+	group, err := r.findUserGroup(ctx, userID)
 	if err != nil {
 		return models.Group{}, nil, err
 	}
 
 	// Затем получаем всех участников этого зала
-	users, err = r.GetGroupMembers(ctx, group.GymID)
+	users, err := r.GetGroupMembers(ctx, group.GymID)
 	if err != nil {
 		return models.Group{}, nil, err
 	}
@@ -69,30 +117,128 @@ func (r *Repository) GetUserGroup(ctx context.Context, userID string) (models.Gr
 	return group, users, nil
 }
 
-// UpdateUserStatus обновляет статус активности пользователя в зале
-func (r *Repository) UpdateUserStatus(ctx context.Context, userID, gymID string, status models.ActivityStatus) error {
+// findUserGroup получает группу зала, в котором состоит пользователь.
+// Залы других организаций не учитываются.
+func (r *Repository) findUserGroup(ctx context.Context, userID string) (models.Group, error) {
 	query := `
-		UPDATE group_members
-		SET status = $1, updated_at = $2
-		WHERE user_id = $3 AND gym_id = $4
-		RETURNING id
+		SELECT g.id, g.gym_id, g.name, g.created_at
+		FROM groups g
+		JOIN group_members gm ON g.gym_id = gm.gym_id
+		JOIN gyms ON gyms.id = g.gym_id
+		WHERE gm.user_id = $1 AND gm.status = ANY($2) AND ($3 = '*' OR gyms.organization_id::text = $3)
+		LIMIT 1
 	`
 
-	var id string
-	err := r.db.QueryRowContext(ctx, query, status, time.Now(), userID, gymID).Scan(&id)
+	var group models.Group
+	err := r.db.GetContext(ctx, &group, query, userID, memberStatuses(), tenantFilter(ctx))
 	if err != nil {
-		return err
+		return models.Group{}, notFound(err, "пользователь не состоит ни в одной группе")
+	}
+
+	return group, nil
+}
+
+// GetGroupMembersVersion вычисляет версию списка участников зала без его загрузки:
+// хэш полей участников, их наград и группы зала и время последнего изменения.
+// Выход участника меняет хэш и время, даже если он больше не попадает в список.
+func (r *Repository) GetGroupMembersVersion(ctx context.Context, gymID string) (models.ListVersion, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.ListVersion{}, err
 	}
 
-	if id == "" {
-		return errors.New("пользователь не найден в этом зале")
+	query := `
+		WITH members AS (
+			SELECT u.id, u.email, u.first_name, u.last_name, gm.status, u.created_at, u.updated_at
+			FROM users u
+			JOIN group_members gm ON u.id = gm.user_id
+			WHERE gm.gym_id = $1 AND gm.status = ANY($2)
+		), badges AS (
+			SELECT mb.user_id, mb.rule_id, mb.awarded_at
+			FROM member_badges mb
+			WHERE mb.gym_id = $1
+		)
+		SELECT
+			md5(concat_ws('#',
+				COALESCE((SELECT concat_ws('|', g.id, g.name) FROM groups g WHERE g.gym_id = $1), ''),
+				COALESCE((SELECT string_agg(concat_ws('|', id, email, first_name, last_name, status, created_at, updated_at), ',' ORDER BY id) FROM members), ''),
+				COALESCE((SELECT string_agg(concat_ws('|', user_id, rule_id, awarded_at), ',' ORDER BY user_id, rule_id) FROM badges), '')
+			)) AS hash,
+			GREATEST(
+				(SELECT updated_at FROM gyms WHERE id = $1),
+				(SELECT MAX(updated_at) FROM members),
+				(SELECT MAX(updated_at) FROM group_members WHERE gym_id = $1),
+				(SELECT MAX(awarded_at) FROM badges)
+			) AS last_modified
+	`
+
+	var version models.ListVersion
+	if err := r.db.GetContext(ctx, &version, query, gymID, memberStatuses()); err != nil {
+		return models.ListVersion{}, err
+	}
+
+	return version, nil
+}
+
+// GetUserGroupVersion вычисляет версию группы пользователя и ее участников
+func (r *Repository) GetUserGroupVersion(ctx context.Context, userID string) (models.ListVersion, error) {
+	group, err := r.findUserGroup(ctx, userID)
+	if err != nil {
+		return models.ListVersion{}, err
+	}
+
+	return r.GetGroupMembersVersion(ctx, group.GymID)
+}
+
+// UpdateUserStatus переводит участника в новый статус и записывает событие в outbox
+// в той же транзакции. check получает текущий статус заблокированной записи и решает,
+// допустим ли переход. Возвращает статус и версию записи после изменения, полученные
+// в той же транзакции.
+func (r *Repository) UpdateUserStatus(ctx context.Context, userID, gymID string, change models.StatusChange, check func(models.ActivityStatus) error) (models.MemberStatus, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.MemberStatus{}, err
+	}
+
+	var current models.MemberStatus
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Блокируем запись участника, чтобы события шли в порядке изменений
+		err := tx.GetContext(ctx, &current, `
+			SELECT status, version
+			FROM group_members
+			WHERE user_id = $1 AND gym_id = $2
+			FOR UPDATE
+		`, userID, gymID)
+		if err != nil {
+			return notFound(err, "пользователь не найден в этом зале")
+		}
+		if !matchesVersion(current.Version, change.ExpectedVersions) {
+			return fmt.Errorf("%w: статус участника изменился, получите его заново", models.ErrPreconditionFailed)
+		}
+
+		previous := current.Status
+		if previous == change.Status {
+			return nil
+		}
+		if err := check(previous); err != nil {
+			return err
+		}
+
+		version, err := r.changeStatus(ctx, tx, userID, gymID, previous, change, utcNow())
+		current = models.MemberStatus{Status: change.Status, Version: version}
+		return err
+	})
+	if err != nil {
+		return models.MemberStatus{}, err
 	}
 
-	return nil
+	return current, nil
 }
 
 // GetUserStatus получает статус пользователя в конкретном зале
 func (r *Repository) GetUserStatus(ctx context.Context, userID, gymID string) (models.ActivityStatus, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return "", err
+	}
+
 	query := `
 		SELECT status
 		FROM group_members
@@ -102,20 +248,141 @@ func (r *Repository) GetUserStatus(ctx context.Context, userID, gymID string) (m
 	var status models.ActivityStatus
 	err := r.db.GetContext(ctx, &status, query, userID, gymID)
 	if err != nil {
-		return "", err
+		return "", notFound(err, "пользователь не найден в этом зале")
 	}
 
 	return status, nil
 }
 
-// AddUserToGym добавляет пользователя в группу зала
-func (r *Repository) AddUserToGym(ctx context.Context, userID, gymID string) error {
+// GetMemberStatus получает статус пользователя в зале вместе с версией записи
+func (r *Repository) GetMemberStatus(ctx context.Context, userID, gymID string) (models.MemberStatus, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.MemberStatus{}, err
+	}
+
 	query := `
-		INSERT INTO group_members (user_id, gym_id, status, joined_at, updated_at)
-		VALUES ($1, $2, $3, $4, $4)
-		ON CONFLICT (user_id, gym_id) DO NOTHING
+		SELECT status, version
+		FROM group_members
+		WHERE user_id = $1 AND gym_id = $2
 	`
 
-	_, err := r.db.ExecContext(ctx, query, userID, gymID, models.ActiveStatus, time.Now())
+	var status models.MemberStatus
+	err := r.db.GetContext(ctx, &status, query, userID, gymID)
+	if err != nil {
+		return models.MemberStatus{}, notFound(err, "пользователь не найден в этом зале")
+	}
+
+	return status, nil
+}
+
+// matchesVersion сообщает, совпадает ли версия записи с одной из ожидаемых.
+// Пустой список ожидаемых версий означает изменение без проверки.
+func matchesVersion(version int64, expected []int64) bool {
+	if len(expected) == 0 {
+		return true
+	}
+	for _, v := range expected {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// AddUserToGym добавляет пользователя в группу зала или возвращает покинувшего зал участника
+// и записывает событие MemberJoined в outbox в той же транзакции. Вступление записывается
+// в историю статусов от имени actor.
+func (r *Repository) AddUserToGym(ctx context.Context, actor models.Actor, userID, gymID string, status models.ActivityStatus) error {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return err
+	}
+
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Пользователь уже связан с залом в другом статусе - ничего не меняем
+		change := models.StatusChange{Status: status, ChangedBy: actor.UserID, ChangedByRole: actor.Role}
+		_, err := r.joinMember(ctx, tx, userID, gymID, change, utcNow())
+		return err
+	})
+}
+
+// recordEvent записывает событие в outbox и уведомляет другие реплики.
+// Уведомление доставляется слушателям только после фиксации транзакции.
+func (r *Repository) recordEvent(ctx context.Context, tx *sqlx.Tx, eventType events.Type, payload events.MemberPayload) error {
+	event, err := insertEvent(ctx, tx, eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	if r.notifier == nil {
+		return nil
+	}
+
+	return r.notifier.Notify(ctx, tx, event)
+}
+
+// txBeginner начинает транзакции: *sqlx.DB или *DB с настройками RLS
+type txBeginner interface {
+	BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+}
+
+// withTx выполняет fn в транзакции и фиксирует ее, если fn завершилась без ошибки
+func withTx(ctx context.Context, db txBeginner, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// utcNow возвращает текущее время в UTC: все метки времени хранятся в UTC,
+// а границы дней и недель вычисляются в часовом поясе зала
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
+
+// notFound заменяет sql.ErrNoRows на models.ErrNotFound с пояснением
+func notFound(err error, message string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", models.ErrNotFound, message)
+	}
 	return err
-}
\ No newline at end of file
+}
+
+// memberStatuses возвращает статусы участников группы как параметр запроса
+func memberStatuses() pq.StringArray {
+	statuses := make(pq.StringArray, len(models.MemberStatuses))
+	for i, status := range models.MemberStatuses {
+		statuses[i] = string(status)
+	}
+	return statuses
+}
+
+// isUniqueViolation проверяет, нарушено ли ограничение уникальности
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isForeignKeyViolation проверяет, нарушен ли внешний ключ. Если указаны имена
+// ограничений, учитываются только они.
+func isForeignKeyViolation(err error, constraints ...string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23503" {
+		return false
+	}
+	if len(constraints) == 0 {
+		return true
+	}
+	for _, c := range constraints {
+		if pqErr.Constraint == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"myapp/internal/tenant"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// DB - подключение репозитория к базе данных. При включенной защите на уровне строк
+// каждый запрос выполняется в транзакции, в которой из контекста заданы app.tenant_id
+// и app.user_id, поэтому политики RLS проверяют организацию независимо от условий
+// в запросах репозитория.
+type DB struct {
+	db *sqlx.DB
+
+	rls  bool
+	role string
+}
+
+// EnableRowLevelSecurity включает передачу организации и пользователя в политики RLS.
+// Если role не пуста, транзакции выполняются от имени этой роли (SET LOCAL ROLE):
+// владелец таблиц политики обходит, поэтому без нее сервис должен подключаться
+// под ролью, которая не владеет таблицами.
+func (r *Repository) EnableRowLevelSecurity(role string) {
+	r.db.rls = true
+	r.db.role = role
+}
+
+// BeginTxx начинает транзакцию и при включенной защите задает в ней настройки RLS
+func (db *DB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	tx, err := db.db.BeginTxx(ctx, opts)
+	if err != nil || !db.rls {
+		return tx, err
+	}
+
+	if err := db.applySettings(ctx, tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// GetContext выполняет запрос и сканирует одну строку в dest
+func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if !db.rls {
+		return db.db.GetContext(ctx, dest, query, args...)
+	}
+
+	return withTx(ctx, db, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, dest, query, args...)
+	})
+}
+
+// SelectContext выполняет запрос и сканирует все строки в dest
+func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if !db.rls {
+		return db.db.SelectContext(ctx, dest, query, args...)
+	}
+
+	return withTx(ctx, db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+// ExecContext выполняет запрос без результата
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if !db.rls {
+		return db.db.ExecContext(ctx, query, args...)
+	}
+
+	var res sql.Result
+	err := withTx(ctx, db, func(tx *sqlx.Tx) error {
+		var err error
+		res, err = tx.ExecContext(ctx, query, args...)
+		return err
+	})
+	return res, err
+}
+
+// applySettings задает роль и настройки RLS до конца транзакции
+func (db *DB) applySettings(ctx context.Context, tx *sqlx.Tx) error {
+	if db.role != "" {
+		if _, err := tx.ExecContext(ctx, `SET LOCAL ROLE `+pq.QuoteIdentifier(db.role)); err != nil {
+			return err
+		}
+	}
+
+	// Контекст без организации и без явного доступа ко всем организациям получает
+	// пустой app.tenant_id и не видит ни одной строки
+	query := `SELECT set_config('app.tenant_id', $1, true), set_config('app.user_id', $2, true)`
+	_, err := tx.ExecContext(ctx, query, tenantFilter(ctx), tenant.UserID(ctx))
+	return err
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"myapp/internal/models"
+	"myapp/internal/tenant"
+)
+
+// getter выполняет запрос с одной строкой результата: *DB, *sqlx.DB или *sqlx.Tx
+type getter interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// allTenants - значение фильтра организации для контекста с доступом ко всем организациям
+const allTenants = "*"
+
+// tenantFilter возвращает значение, с которым запросы сравнивают организацию:
+// организацию из контекста, allTenants для контекста с доступом ко всем организациям
+// или пустую строку, с которой не совпадает ни одна организация.
+// Это же значение получает app.tenant_id в режиме RLS.
+func tenantFilter(ctx context.Context) string {
+	if tenant.AllOrganizations(ctx) {
+		return allTenants
+	}
+	return tenant.OrganizationID(ctx)
+}
+
+// checkGym проверяет, что зал принадлежит организации из контекста.
+// Зал другой организации неотличим от несуществующего, поэтому чужие данные
+// нельзя ни прочитать, ни изменить. Контекст с доступом ко всем организациям не ограничен.
+func checkGym(ctx context.Context, q getter, gymID string) error {
+	orgID := tenantFilter(ctx)
+	if orgID == allTenants {
+		return nil
+	}
+
+	var owned bool
+	query := `SELECT EXISTS (SELECT 1 FROM gyms WHERE id::text = $1 AND organization_id::text = $2)`
+	if err := q.GetContext(ctx, &owned, query, gymID, orgID); err != nil {
+		return err
+	}
+	if !owned {
+		return fmt.Errorf("%w: зал не найден", models.ErrNotFound)
+	}
+
+	return nil
+}
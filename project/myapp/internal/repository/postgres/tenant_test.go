@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"myapp/internal/models"
+	"myapp/internal/tenant"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Тесты репозитория выполняются на базе из TEST_DATABASE_URL с примененными
+// миграциями; без нее они пропускаются. В CI база поднимается в
+// .github/workflows/test.yml, локально - так же:
+//
+//	for f in migrations/*.up.sql; do psql "$TEST_DATABASE_URL" -v ON_ERROR_STOP=1 -f "$f"; done
+//	go test ./internal/repository/postgres/
+//
+// Каждый тест создает свои организации, залы и пользователей и удаляет их по завершении.
+
+// testDB подключается к тестовой базе
+func testDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL не задан")
+	}
+
+	db, err := sqlx.Connect("postgres", url)
+	if err != nil {
+		t.Fatalf("подключение к тестовой базе: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// tenantFixture - две организации с залом в каждой и пользователь, состоящий в зале B
+type tenantFixture struct {
+	db   *sqlx.DB
+	repo *Repository
+
+	gymA, gymB    models.Gym
+	userB, emailB string
+	suffix        string
+	userIDs       pq.StringArray
+	ctxA, ctxB    context.Context
+}
+
+// newTenantFixture создает данные двух организаций
+func newTenantFixture(t *testing.T) *tenantFixture {
+	t.Helper()
+
+	db := testDB(t)
+	f := &tenantFixture{db: db, repo: NewRepository(db, nil)}
+	ctx := tenant.Unscoped(context.Background())
+	f.suffix = fmt.Sprintf("%d", time.Now().UnixNano())
+	suffix := f.suffix
+
+	var orgIDs, gymIDs pq.StringArray
+	t.Cleanup(func() {
+		statements := []struct {
+			query string
+			ids   pq.StringArray
+		}{
+			{`DELETE FROM group_members WHERE gym_id::text = ANY($1)`, gymIDs},
+			{`DELETE FROM gyms WHERE id::text = ANY($1)`, gymIDs},
+			{`DELETE FROM organizations WHERE id::text = ANY($1)`, orgIDs},
+			{`DELETE FROM users WHERE id::text = ANY($1)`, f.userIDs},
+		}
+		for _, s := range statements {
+			if _, err := db.Exec(s.query, s.ids); err != nil {
+				t.Errorf("очистка тестовых данных: %v", err)
+			}
+		}
+	})
+
+	for _, name := range []string{"A", "B"} {
+		org, err := f.repo.CreateOrganization(ctx, models.Organization{Name: "org " + name + " " + suffix})
+		if err != nil {
+			t.Fatalf("создание организации %s: %v", name, err)
+		}
+		orgIDs = append(orgIDs, org.ID)
+
+		gym, err := f.repo.CreateGym(ctx, models.Gym{
+			OrganizationID: org.ID,
+			Name:           "gym " + name + " " + suffix,
+			Timezone:       models.DefaultTimezone,
+		})
+		if err != nil {
+			t.Fatalf("создание зала %s: %v", name, err)
+		}
+		gymIDs = append(gymIDs, gym.ID)
+
+		if name == "A" {
+			f.gymA, f.ctxA = gym, tenant.WithOrganization(ctx, org.ID)
+		} else {
+			f.gymB, f.ctxB = gym, tenant.WithOrganization(ctx, org.ID)
+		}
+	}
+
+	f.userB = f.newUserID(t)
+	f.emailB = "tenant-b-" + suffix + "@example.com"
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, first_name, last_name)
+		VALUES ($1, $2, 'Tenant', 'B')
+	`, f.userB, f.emailB)
+	if err != nil {
+		t.Fatalf("создание пользователя: %v", err)
+	}
+
+	if err := f.repo.AddUserToGym(f.ctxB, memberActor(f.userB), f.userB, f.gymB.ID, models.ActiveStatus); err != nil {
+		t.Fatalf("добавление пользователя в зал B: %v", err)
+	}
+
+	return f
+}
+
+// newUserID возвращает ID для нового пользователя и удаляет пользователя по завершении теста
+func (f *tenantFixture) newUserID(t *testing.T) string {
+	t.Helper()
+
+	var id string
+	if err := f.db.Get(&id, `SELECT gen_random_uuid()::text`); err != nil {
+		t.Fatalf("генерация ID пользователя: %v", err)
+	}
+	f.userIDs = append(f.userIDs, id)
+	return id
+}
+
+// memberActor возвращает пользователя userID как вызывающую сторону
+func memberActor(userID string) models.Actor {
+	return models.Actor{UserID: userID, Role: models.RoleUser}
+}
+
+// expectNotFound проверяет, что операция над чужими данными неотличима от отсутствующих
+func expectNotFound(t *testing.T, op string, err error) {
+	t.Helper()
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("%s: ожидалась ошибка ErrNotFound, получено %v", op, err)
+	}
+}
+
+func TestCheckGym(t *testing.T) {
+	f := newTenantFixture(t)
+
+	if err := checkGym(f.ctxA, f.repo.db, f.gymA.ID); err != nil {
+		t.Errorf("свой зал: %v", err)
+	}
+	expectNotFound(t, "зал другой организации", checkGym(f.ctxA, f.repo.db, f.gymB.ID))
+	expectNotFound(t, "несуществующий зал", checkGym(f.ctxA, f.repo.db, "00000000-0000-0000-0000-000000000000"))
+
+	if err := checkGym(tenant.Unscoped(context.Background()), f.repo.db, f.gymB.ID); err != nil {
+		t.Errorf("контекст со всеми организациями: %v", err)
+	}
+	expectNotFound(t, "контекст без организации", checkGym(context.Background(), f.repo.db, f.gymB.ID))
+}
+
+func TestGymsCrossTenant(t *testing.T) {
+	f := newTenantFixture(t)
+
+	if _, err := f.repo.GetGym(f.ctxA, f.gymA.ID); err != nil {
+		t.Errorf("GetGym своего зала: %v", err)
+	}
+	_, err := f.repo.GetGym(f.ctxA, f.gymB.ID)
+	expectNotFound(t, "GetGym", err)
+
+	gyms, err := f.repo.ListGyms(f.ctxA)
+	if err != nil {
+		t.Fatalf("ListGyms: %v", err)
+	}
+	for _, gym := range gyms {
+		if gym.OrganizationID != f.gymA.OrganizationID {
+			t.Errorf("ListGyms вернул зал %s другой организации", gym.ID)
+		}
+	}
+
+	renamed := f.gymB
+	renamed.Name = "renamed by A"
+	_, err = f.repo.UpdateGym(f.ctxA, renamed)
+	expectNotFound(t, "UpdateGym", err)
+
+	expectNotFound(t, "DeleteGym", f.repo.DeleteGym(f.ctxA, f.gymB.ID))
+
+	gym, err := f.repo.GetGym(f.ctxB, f.gymB.ID)
+	if err != nil {
+		t.Fatalf("зал B после операций A: %v", err)
+	}
+	if gym.Name != f.gymB.Name {
+		t.Errorf("организация A переименовала зал B: %q", gym.Name)
+	}
+}
+
+func TestMembersCrossTenant(t *testing.T) {
+	f := newTenantFixture(t)
+
+	_, err := f.repo.GetGroupMembers(f.ctxA, f.gymB.ID)
+	expectNotFound(t, "GetGroupMembers", err)
+
+	_, err = f.repo.GetUserStatus(f.ctxA, f.userB, f.gymB.ID)
+	expectNotFound(t, "GetUserStatus", err)
+
+	_, err = f.repo.GetGroupMembersVersion(f.ctxA, f.gymB.ID)
+	expectNotFound(t, "GetGroupMembersVersion", err)
+
+	_, _, err = f.repo.GetUserGroup(f.ctxA, f.userB)
+	expectNotFound(t, "GetUserGroup", err)
+
+	change := models.StatusChange{Status: models.SuspendedStatus, Reason: "cross-tenant"}
+	_, err = f.repo.UpdateUserStatus(f.ctxA, f.userB, f.gymB.ID, change, func(models.ActivityStatus) error { return nil })
+	expectNotFound(t, "UpdateUserStatus", err)
+
+	expectNotFound(t, "AddUserToGym", f.repo.AddUserToGym(f.ctxA, memberActor(f.userB), f.userB, f.gymB.ID, models.ActiveStatus))
+
+	status, err := f.repo.GetUserStatus(f.ctxB, f.userB, f.gymB.ID)
+	if err != nil {
+		t.Fatalf("статус в зале B: %v", err)
+	}
+	if status != models.ActiveStatus {
+		t.Errorf("организация A изменила статус участника зала B: %s", status)
+	}
+}
+
+func TestDeleteGymWithFormerMembers(t *testing.T) {
+	f := newTenantFixture(t)
+
+	err := f.repo.DeleteGym(f.ctxB, f.gymB.ID)
+	if !errors.Is(err, models.ErrConflict) {
+		t.Fatalf("зал с участником: ожидалась ошибка ErrConflict, получено %v", err)
+	}
+
+	change := models.StatusChange{Status: models.LeftStatus, ChangedBy: f.userB}
+	allowAll := func(models.ActivityStatus) error { return nil }
+	if _, err := f.repo.UpdateUserStatus(f.ctxB, f.userB, f.gymB.ID, change, allowAll); err != nil {
+		t.Fatalf("выход участника: %v", err)
+	}
+
+	if err := f.repo.DeleteGym(f.ctxB, f.gymB.ID); err != nil {
+		t.Errorf("зал только с бывшими участниками: %v", err)
+	}
+}
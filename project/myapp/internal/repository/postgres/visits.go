@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"myapp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// visitDedupeWindow - интервал, в пределах которого повторная отметка того же участника
+// в том же зале считается тем же посещением
+const visitDedupeWindow = 30 * time.Minute
+
+// CreateVisit сохраняет посещение зала и в той же транзакции обновляет сводку
+// посещений и серий участника и выдает заработанные награды. Новые награды возвращаются в NewBadges.
+// Посещение ближе visitDedupeWindow к уже отмеченному отклоняется с ErrConflict:
+// одновременные отметки упорядочиваются блокировкой строки участника.
+func (r *Repository) CreateVisit(ctx context.Context, visit models.Visit, streakMinVisits int) (models.Visit, error) {
+	if err := checkGym(ctx, r.db, visit.GymID); err != nil {
+		return models.Visit{}, err
+	}
+
+	var created models.Visit
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var locked int
+		query := `SELECT 1 FROM group_members WHERE user_id = $1 AND gym_id = $2 FOR UPDATE`
+		if err := tx.GetContext(ctx, &locked, query, visit.UserID, visit.GymID); err != nil {
+			return notFound(err, "пользователь не состоит в группе зала")
+		}
+
+		query = `
+			INSERT INTO visits (user_id, gym_id, visited_at)
+			SELECT $1::uuid, $2::uuid, $3::timestamptz
+			WHERE NOT EXISTS (
+				SELECT 1 FROM visits
+				WHERE gym_id = $2 AND user_id = $1
+					AND visited_at > $4 AND visited_at < $5
+			)
+			RETURNING id, user_id, gym_id, visited_at
+		`
+		err := tx.GetContext(ctx, &created, query,
+			visit.UserID, visit.GymID, visit.VisitedAt,
+			visit.VisitedAt.Add(-visitDedupeWindow), visit.VisitedAt.Add(visitDedupeWindow))
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: посещение уже отмечено", models.ErrConflict)
+		}
+		if err != nil {
+			return err
+		}
+
+		created.NewBadges, err = updateMemberStats(ctx, tx, visit.GymID, visit.UserID, created.VisitedAt, streakMinVisits)
+		return err
+	})
+	if err != nil {
+		return models.Visit{}, err
+	}
+
+	return created, nil
+}
+
+// GetLeaderboard строит рейтинг участников зала по числу посещений с since и текущей серии недель.
+// Рейтинг считается в базе оконными функциями по посещениям и сводке member_stats;
+// возвращаются первые limit мест и место userID. Серия считается текущей, если последняя
+// засчитанная неделя - текущая или предыдущая относительно now по местному времени зала.
+// При равенстве выше тот, у кого длиннее серия, затем тот, кто раньше сделал последнее посещение.
+func (r *Repository) GetLeaderboard(ctx context.Context, gymID string, since, now time.Time, userID string, limit int) ([]models.LeaderboardEntry, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH counts AS (
+			SELECT gm.user_id, COUNT(v.id) AS visits, MAX(v.visited_at) AS last_visit_at
+			FROM group_members gm
+			LEFT JOIN visits v ON v.gym_id = gm.gym_id AND v.user_id = gm.user_id AND v.visited_at >= $2
+			WHERE gm.gym_id = $1 AND gm.status = ANY($3)
+			GROUP BY gm.user_id
+		),
+		streaks AS (
+			SELECT ms.user_id, ms.current_streak AS streak
+			FROM member_stats ms
+			JOIN gyms g ON g.id = ms.gym_id
+			WHERE ms.gym_id = $1
+				AND ms.streak_week >= (date_trunc('week', $4::timestamptz AT TIME ZONE g.timezone) - INTERVAL '1 week') AT TIME ZONE g.timezone
+		),
+		ranked AS (
+			SELECT c.user_id, u.first_name, u.last_name, c.visits, COALESCE(s.streak, 0) AS streak,
+				ROW_NUMBER() OVER (
+					ORDER BY c.visits DESC, COALESCE(s.streak, 0) DESC, c.last_visit_at ASC NULLS LAST, c.user_id
+				) AS rank
+			FROM counts c
+			JOIN users u ON u.id = c.user_id
+			LEFT JOIN streaks s ON s.user_id = c.user_id
+		)
+		SELECT rank, user_id, first_name, last_name, visits, streak
+		FROM ranked
+		WHERE rank <= $5 OR user_id::text = $6
+		ORDER BY rank
+	`
+
+	entries := []models.LeaderboardEntry{}
+	err := r.db.SelectContext(ctx, &entries, query, gymID, since, memberStatuses(), now, limit, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
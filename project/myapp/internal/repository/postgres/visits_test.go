@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"myapp/internal/models"
+)
+
+func TestCreateVisitDedupe(t *testing.T) {
+	f := newTenantFixture(t)
+	now := time.Now().UTC()
+
+	visit := func(at time.Time) error {
+		_, err := f.repo.CreateVisit(f.ctxB, models.Visit{UserID: f.userB, GymID: f.gymB.ID, VisitedAt: at}, 1)
+		return err
+	}
+
+	if err := visit(now.Add(-3 * time.Hour)); err != nil {
+		t.Fatalf("первое посещение: %v", err)
+	}
+	if err := visit(now.Add(-3*time.Hour + visitDedupeWindow/2)); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("повторная отметка: ожидалась ошибка ErrConflict, получено %v", err)
+	}
+	if err := visit(now); err != nil {
+		t.Errorf("посещение после интервала: %v", err)
+	}
+}
+
+func TestCreateVisitUpdatesStatsIncrementally(t *testing.T) {
+	f := newTenantFixture(t)
+	week := 7 * 24 * time.Hour
+	now := time.Now().UTC()
+
+	// Две недели подряд, пропуск, текущая неделя и посещение задним числом в пропущенную
+	visits := []time.Time{
+		now.Add(-4 * week),
+		now.Add(-3 * week),
+		now.Add(-3*week + time.Hour),
+		now,
+		now.Add(-2 * week),
+	}
+	for i, at := range visits {
+		_, err := f.repo.CreateVisit(f.ctxB, models.Visit{UserID: f.userB, GymID: f.gymB.ID, VisitedAt: at}, 1)
+		if err != nil {
+			t.Fatalf("посещение %d: %v", i, err)
+		}
+
+		incremental, err := f.repo.GetMemberStats(f.ctxB, f.gymB.ID, f.userB)
+		if err != nil {
+			t.Fatalf("сводка после посещения %d: %v", i, err)
+		}
+		if _, _, err := f.repo.RefreshMemberStats(f.ctxB, f.gymB.ID, f.userB, 1); err != nil {
+			t.Fatalf("пересчет после посещения %d: %v", i, err)
+		}
+		full, err := f.repo.GetMemberStats(f.ctxB, f.gymB.ID, f.userB)
+		if err != nil {
+			t.Fatalf("сводка после пересчета %d: %v", i, err)
+		}
+
+		if incremental.TotalVisits != full.TotalVisits ||
+			incremental.CurrentStreak != full.CurrentStreak ||
+			incremental.LongestStreak != full.LongestStreak ||
+			(incremental.StreakWeek == nil) != (full.StreakWeek == nil) ||
+			(full.StreakWeek != nil && !incremental.StreakWeek.Equal(*full.StreakWeek)) {
+			t.Errorf("посещение %d: сводка %+v не совпадает с пересчетом %+v", i, incremental, full)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"myapp/internal/events"
+	"myapp/internal/models"
+	"myapp/internal/webhooks"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WebhookRepository хранит подписки на вебхуки и журнал доставок
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebhookRepository создает новый репозиторий вебхуков
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+const webhookColumns = `id, gym_id, url, secret, event_types, enabled, consecutive_failures, disabled_reason, created_at, updated_at`
+
+// CreateWebhook сохраняет новую подписку
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	if err := checkGym(ctx, r.db, webhook.GymID); err != nil {
+		return models.Webhook{}, err
+	}
+
+	query := `
+		INSERT INTO webhooks (gym_id, url, secret, event_types, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING ` + webhookColumns
+
+	var created models.Webhook
+	err := r.db.GetContext(ctx, &created, query, webhook.GymID, webhook.URL, webhook.Secret, webhook.EventTypes, webhook.Enabled, utcNow())
+	return created, err
+}
+
+// ListWebhooks получает подписки зала
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, gymID string) ([]models.Webhook, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE gym_id = $1 ORDER BY created_at`
+
+	webhooksList := []models.Webhook{}
+	if err := r.db.SelectContext(ctx, &webhooksList, query, gymID); err != nil {
+		return nil, err
+	}
+
+	return webhooksList, nil
+}
+
+// GetWebhook получает подписку зала
+func (r *WebhookRepository) GetWebhook(ctx context.Context, gymID, webhookID string) (models.Webhook, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.Webhook{}, err
+	}
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE gym_id = $1 AND id = $2`
+
+	var webhook models.Webhook
+	if err := r.db.GetContext(ctx, &webhook, query, gymID, webhookID); err != nil {
+		return models.Webhook{}, notFound(err, "вебхук не найден")
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook сохраняет изменения подписки
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	if err := checkGym(ctx, r.db, webhook.GymID); err != nil {
+		return models.Webhook{}, err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = $1, secret = $2, event_types = $3, enabled = $4,
+			consecutive_failures = $5, disabled_reason = $6, updated_at = $7
+		WHERE gym_id = $8 AND id = $9
+		RETURNING ` + webhookColumns
+
+	var updated models.Webhook
+	err := r.db.GetContext(ctx, &updated, query,
+		webhook.URL, webhook.Secret, webhook.EventTypes, webhook.Enabled,
+		webhook.ConsecutiveFailures, webhook.DisabledReason, utcNow(),
+		webhook.GymID, webhook.ID)
+	if err != nil {
+		return models.Webhook{}, notFound(err, "вебхук не найден")
+	}
+
+	return updated, nil
+}
+
+// DeleteWebhook удаляет подписку зала
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, gymID, webhookID string) error {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE gym_id = $1 AND id = $2`, gymID, webhookID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: вебхук не найден", models.ErrNotFound)
+	}
+
+	return nil
+}
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at`
+
+// ListDeliveries получает последние доставки вебхука
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, gymID, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE w.gym_id = $1 AND w.id = $2
+		ORDER BY d.created_at DESC
+		LIMIT $3
+	`
+
+	deliveries := []models.WebhookDelivery{}
+	if err := r.db.SelectContext(ctx, &deliveries, query, gymID, webhookID, limit); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver возвращает доставку в очередь с обнуленным счетчиком попыток
+func (r *WebhookRepository) Redeliver(ctx context.Context, gymID, webhookID, deliveryID string) (models.WebhookDelivery, error) {
+	if err := checkGym(ctx, r.db, gymID); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	query := `
+		UPDATE webhook_deliveries d
+		SET status = $1, attempts = 0, next_attempt_at = $2
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND w.gym_id = $3 AND w.id = $4 AND d.id = $5
+		RETURNING ` + deliveryColumns
+
+	var delivery models.WebhookDelivery
+	err := r.db.GetContext(ctx, &delivery, query, models.DeliveryPending, utcNow(), gymID, webhookID, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, notFound(err, "доставка не найдена")
+	}
+
+	return delivery, nil
+}
+
+// EnqueueDeliveries создает доставки события для включенных вебхуков зала,
+// подписанных на этот тип события или на все события
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, gymID string, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		SELECT id, $1, $2, $3, $4, $5, $5
+		FROM webhooks
+		WHERE gym_id = $6 AND enabled
+			AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`
+
+	_, err = r.db.ExecContext(ctx, query, event.ID, string(event.Type), payload, models.DeliveryPending, utcNow(), gymID)
+	return err
+}
+
+// ClaimDueDeliveries берет в работу доставки, время которых наступило.
+// SKIP LOCKED позволяет нескольким репликам разбирать очередь параллельно.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhooks.Job, error) {
+	now := utcNow()
+	query := `
+		WITH due AS (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = $1 AND d.next_attempt_at <= $2 AND w.enabled
+			ORDER BY d.next_attempt_at
+			LIMIT $3
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $4
+		FROM due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING ` + deliveryColumns + `, w.url, w.secret
+	`
+
+	var jobs []webhooks.Job
+	if err := r.db.SelectContext(ctx, &jobs, query, models.DeliveryPending, now, limit, now.Add(lease)); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// CompleteDelivery отмечает доставку успешной и сбрасывает счетчик ошибок вебхука
+func (r *WebhookRepository) CompleteDelivery(ctx context.Context, job webhooks.Job, statusCode int) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		now := utcNow()
+		query := `
+			UPDATE webhook_deliveries
+			SET status = $1, attempts = attempts + 1, last_status_code = $2,
+				last_error = NULL, next_attempt_at = NULL, delivered_at = $3
+			WHERE id = $4
+		`
+		if _, err := tx.ExecContext(ctx, query, models.DeliverySucceeded, statusCode, now, job.ID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE webhooks SET consecutive_failures = 0 WHERE id = $1`, job.WebhookID)
+		return err
+	})
+}
+
+// FailDelivery сохраняет неудачную попытку и отключает вебхук после disableAfter ошибок подряд
+func (r *WebhookRepository) FailDelivery(ctx context.Context, job webhooks.Job, statusCode int, message string, nextAttempt *time.Time, disableAfter int) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		status := models.DeliveryPending
+		if nextAttempt == nil {
+			status = models.DeliveryFailed
+		}
+
+		var code *int
+		if statusCode != 0 {
+			code = &statusCode
+		}
+
+		query := `
+			UPDATE webhook_deliveries
+			SET status = $1, attempts = attempts + 1, last_status_code = $2,
+				last_error = $3, next_attempt_at = $4
+			WHERE id = $5
+		`
+		if _, err := tx.ExecContext(ctx, query, status, code, message, nextAttempt, job.ID); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE webhooks
+			SET consecutive_failures = consecutive_failures + 1,
+				enabled = enabled AND consecutive_failures + 1 < $1,
+				disabled_reason = CASE
+					WHEN enabled AND consecutive_failures + 1 >= $1 THEN $2
+					ELSE disabled_reason
+				END,
+				updated_at = $3
+			WHERE id = $4
+		`
+		reason := fmt.Sprintf("отключен автоматически после %d ошибок доставки подряд", disableAfter)
+		_, err := tx.ExecContext(ctx, query, disableAfter, reason, utcNow(), job.WebhookID)
+		return err
+	})
+}
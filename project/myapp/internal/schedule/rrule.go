@@ -0,0 +1,225 @@
+// Package schedule разбирает правила повторения занятий в духе RRULE (RFC 5545)
+// и вычисляет даты занятий в часовом поясе зала.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency определяет частоту повторения
+type Frequency string
+
+// Поддерживаемые частоты повторения
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// maxIterations ограничивает перебор периодов, чтобы ошибочное правило не зациклило расчет
+const maxIterations = 100000
+
+// weekdays сопоставляет дни недели RRULE с time.Weekday
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule представляет правило повторения. Нулевое значение означает однократное занятие.
+// Поддерживается подмножество RRULE: FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL,
+// BYDAY (только для WEEKLY), COUNT и UNTIL.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	Count    int
+	Until    time.Time
+}
+
+// ParseRule разбирает правило вида "FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,WE;COUNT=10".
+// Префикс "RRULE:" допускается. Пустая строка означает однократное занятие.
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, nil
+	}
+
+	rule := Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("недопустимая часть правила %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+			switch rule.Freq {
+			case Daily, Weekly, Monthly:
+			default:
+				return Rule{}, fmt.Errorf("неподдерживаемая частота %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("недопустимый INTERVAL %q", value)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					return Rule{}, fmt.Errorf("недопустимый день недели %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("недопустимый COUNT %q", value)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("недопустимый UNTIL %q", value)
+			}
+			rule.Until = until
+		default:
+			return Rule{}, fmt.Errorf("неподдерживаемый параметр %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, errors.New("требуется FREQ")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return Rule{}, errors.New("BYDAY поддерживается только для FREQ=WEEKLY")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, errors.New("COUNT и UNTIL нельзя указывать одновременно")
+	}
+
+	sort.Slice(rule.ByDay, func(i, j int) bool {
+		return mondayOffset(rule.ByDay[i]) < mondayOffset(rule.ByDay[j])
+	})
+
+	return rule, nil
+}
+
+// parseUntil разбирает UNTIL в форматах RRULE: дата или дата-время в UTC
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// Дата без времени включает весь день
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("недопустимый формат")
+}
+
+// IsZero сообщает, описывает ли правило однократное занятие
+func (r Rule) IsZero() bool {
+	return r.Freq == ""
+}
+
+// Occurrences возвращает начала занятий серии, начинающейся в start, попадающие в [from, to).
+// Время занятий сохраняется по местным часам часового пояса start, в том числе при переходе
+// на летнее время. COUNT отсчитывается от начала серии, а не от from.
+func (r Rule) Occurrences(start, from, to time.Time) []time.Time {
+	var result []time.Time
+	if r.IsZero() {
+		if !start.Before(from) && start.Before(to) {
+			result = append(result, start)
+		}
+		return result
+	}
+
+	emitted := 0
+	for period := 0; period < maxIterations; period++ {
+		for _, t := range r.candidates(start, period) {
+			if t.Before(start) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return result
+			}
+			if !t.Before(to) {
+				return result
+			}
+			emitted++
+			if !t.Before(from) {
+				result = append(result, t)
+			}
+			if r.Count > 0 && emitted >= r.Count {
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// Includes сообщает, является ли t началом одного из занятий серии
+func (r Rule) Includes(start, t time.Time) bool {
+	for _, occurrence := range r.Occurrences(start, t, t.Add(time.Second)) {
+		if occurrence.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates возвращает возможные начала занятий в периоде с номером period
+func (r Rule) candidates(start time.Time, period int) []time.Time {
+	loc := start.Location()
+	hour, min, sec := start.Clock()
+	year, month, day := start.Date()
+	step := period * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		return []time.Time{time.Date(year, month, day+step, hour, min, sec, 0, loc)}
+
+	case Weekly:
+		// Неделя начинается с понедельника
+		monday := day - mondayOffset(start.Weekday()) + step*7
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		result := make([]time.Time, 0, len(days))
+		for _, weekday := range days {
+			result = append(result, time.Date(year, month, monday+mondayOffset(weekday), hour, min, sec, 0, loc))
+		}
+		return result
+
+	case Monthly:
+		t := time.Date(year, month+time.Month(step), day, hour, min, sec, 0, loc)
+		// Месяцы без такого числа пропускаются, как в RFC 5545
+		if t.Day() != day {
+			return nil
+		}
+		return []time.Time{t}
+	}
+
+	return nil
+}
+
+// mondayOffset возвращает номер дня недели, считая понедельник нулевым
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
@@ -0,0 +1,183 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustRule(t *testing.T, s string) Rule {
+	t.Helper()
+	rule, err := ParseRule(s)
+	if err != nil {
+		t.Fatalf("ParseRule(%q): %v", s, err)
+	}
+	return rule
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		wantErr bool
+	}{
+		{"", false},
+		{"RRULE:FREQ=WEEKLY;BYDAY=WE,MO;COUNT=10", false},
+		{"FREQ=DAILY;INTERVAL=2;UNTIL=20240110", false},
+		{"FREQ=DAILY;UNTIL=20240110T090000Z", false},
+		{"INTERVAL=2", true},
+		{"FREQ=YEARLY", true},
+		{"FREQ=DAILY;INTERVAL=0", true},
+		{"FREQ=MONTHLY;BYDAY=MO", true},
+		{"FREQ=DAILY;COUNT=3;UNTIL=20240110", true},
+		{"FREQ=WEEKLY;BYDAY=XX", true},
+		{"FREQ=DAILY;BYHOUR=10", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := ParseRule(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ожидалась ошибка: %v, получено %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	rule := mustRule(t, "FREQ=WEEKLY;BYDAY=SU,MO")
+	if rule.ByDay[0] != time.Monday || rule.ByDay[1] != time.Sunday {
+		t.Errorf("BYDAY должен быть упорядочен с понедельника, получено %v", rule.ByDay)
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	at := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, berlin)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		from  time.Time
+		to    time.Time
+		want  []time.Time
+	}{
+		{
+			name:  "однократное занятие",
+			start: at(2024, 1, 10, 18),
+			from:  at(2024, 1, 1, 0),
+			to:    at(2024, 2, 1, 0),
+			want:  []time.Time{at(2024, 1, 10, 18)},
+		},
+		{
+			name:  "однократное занятие вне окна",
+			start: at(2024, 1, 10, 18),
+			from:  at(2024, 1, 11, 0),
+			to:    at(2024, 2, 1, 0),
+		},
+		{
+			// 31 марта 2024 года Берлин переходит на летнее время
+			name:  "переход на летнее время сохраняет местное время",
+			rule:  "FREQ=DAILY",
+			start: at(2024, 3, 30, 10),
+			from:  at(2024, 3, 30, 0),
+			to:    at(2024, 4, 2, 0),
+			want:  []time.Time{at(2024, 3, 30, 10), at(2024, 3, 31, 10), at(2024, 4, 1, 10)},
+		},
+		{
+			// 27 октября 2024 года Берлин возвращается на зимнее время
+			name:  "переход на зимнее время в еженедельной серии",
+			rule:  "FREQ=WEEKLY;BYDAY=SA,SU",
+			start: at(2024, 10, 26, 9),
+			from:  at(2024, 10, 26, 0),
+			to:    at(2024, 10, 28, 0),
+			want:  []time.Time{at(2024, 10, 26, 9), at(2024, 10, 27, 9)},
+		},
+		{
+			name:  "COUNT отсчитывается от начала серии",
+			rule:  "FREQ=DAILY;COUNT=5",
+			start: at(2024, 1, 1, 10),
+			from:  at(2024, 1, 4, 0),
+			to:    at(2024, 2, 1, 0),
+			want:  []time.Time{at(2024, 1, 4, 10), at(2024, 1, 5, 10)},
+		},
+		{
+			name:  "COUNT исчерпан до начала окна",
+			rule:  "FREQ=WEEKLY;COUNT=2",
+			start: at(2024, 1, 1, 10),
+			from:  at(2024, 1, 15, 0),
+			to:    at(2024, 2, 1, 0),
+		},
+		{
+			name:  "BYDAY не включает дни до начала серии",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=4",
+			start: at(2024, 1, 3, 19),
+			from:  at(2024, 1, 1, 0),
+			to:    at(2024, 2, 1, 0),
+			want:  []time.Time{at(2024, 1, 3, 19), at(2024, 1, 5, 19), at(2024, 1, 8, 19), at(2024, 1, 10, 19)},
+		},
+		{
+			name:  "INTERVAL через неделю",
+			rule:  "FREQ=WEEKLY;INTERVAL=2",
+			start: at(2024, 1, 2, 8),
+			from:  at(2024, 1, 1, 0),
+			to:    at(2024, 2, 1, 0),
+			want:  []time.Time{at(2024, 1, 2, 8), at(2024, 1, 16, 8), at(2024, 1, 30, 8)},
+		},
+		{
+			name:  "месяцы без такого числа пропускаются",
+			rule:  "FREQ=MONTHLY;COUNT=4",
+			start: at(2024, 1, 31, 12),
+			from:  at(2024, 1, 1, 0),
+			to:    at(2025, 1, 1, 0),
+			want:  []time.Time{at(2024, 1, 31, 12), at(2024, 3, 31, 12), at(2024, 5, 31, 12), at(2024, 7, 31, 12)},
+		},
+		{
+			name:  "UNTIL в виде даты включает весь день",
+			rule:  "FREQ=DAILY;UNTIL=20240103",
+			start: at(2024, 1, 1, 10),
+			from:  at(2024, 1, 1, 0),
+			to:    at(2024, 2, 1, 0),
+			want:  []time.Time{at(2024, 1, 1, 10), at(2024, 1, 2, 10), at(2024, 1, 3, 10)},
+		},
+		{
+			name:  "UNTIL в виде даты и времени",
+			rule:  "FREQ=DAILY;UNTIL=20240103T080000Z",
+			start: at(2024, 1, 1, 10),
+			from:  at(2024, 1, 1, 0),
+			to:    at(2024, 2, 1, 0),
+			want:  []time.Time{at(2024, 1, 1, 10), at(2024, 1, 2, 10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mustRule(t, tt.rule).Occurrences(tt.start, tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ожидалось %v, получено %v", tt.want, got)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("занятие %d: ожидалось %v, получено %v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	rule := mustRule(t, "FREQ=DAILY;INTERVAL=2")
+
+	if !rule.Includes(start, start.AddDate(0, 0, 4)) {
+		t.Error("ожидалось занятие через четыре дня")
+	}
+	if rule.Includes(start, start.AddDate(0, 0, 3)) {
+		t.Error("не ожидалось занятие через три дня")
+	}
+	if rule.Includes(start, start.AddDate(0, 0, 4).Add(time.Hour)) {
+		t.Error("не ожидалось занятие в другое время")
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"myapp/internal/models"
+)
+
+// badgeCodePattern проверяет код награды
+var badgeCodePattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+// GetMemberStats возвращает посещения, серии и награды участника зала
+func (s *Service) GetMemberStats(ctx context.Context, actor models.Actor, gymID, userID string) (models.MemberStats, error) {
+	if gymID == "" || userID == "" {
+		return models.MemberStats{}, fmt.Errorf("%w: требуются ID зала и ID пользователя", models.ErrInvalidArgument)
+	}
+	if err := s.requireMember(ctx, actor, gymID, false); err != nil {
+		return models.MemberStats{}, err
+	}
+
+	stats, err := s.repo.GetMemberStats(ctx, gymID, userID)
+	if err != nil {
+		return models.MemberStats{}, err
+	}
+
+	loc, err := s.gymLocation(ctx, gymID)
+	if err != nil {
+		return models.MemberStats{}, err
+	}
+
+	// Серия прерывается, если ни текущая, ни прошлая неделя зала не засчитаны
+	if stats.StreakWeek == nil || stats.StreakWeek.Before(startOfWeek(time.Now().In(loc)).AddDate(0, 0, -7)) {
+		stats.CurrentStreak = 0
+	}
+
+	if stats.Badges, err = s.repo.ListMemberBadges(ctx, gymID, userID); err != nil {
+		return models.MemberStats{}, err
+	}
+
+	return stats, nil
+}
+
+// RecomputeBadges пересчитывает серии всех участников зала и выдает недостающие награды,
+// например после изменения правил наград или порога серии
+func (s *Service) RecomputeBadges(ctx context.Context, gymID string) (models.RecomputeResult, error) {
+	if gymID == "" {
+		return models.RecomputeResult{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	settings, err := s.repo.GetGymSettings(ctx, gymID)
+	if err != nil {
+		return models.RecomputeResult{}, err
+	}
+
+	members, badges, err := s.repo.RefreshMemberStats(ctx, gymID, "", settings.StreakMinVisits)
+	if err != nil {
+		return models.RecomputeResult{}, err
+	}
+
+	return models.RecomputeResult{Members: members, Awarded: len(badges)}, nil
+}
+
+// ListBadgeRules возвращает правила наград, действующие в зале
+func (s *Service) ListBadgeRules(ctx context.Context, gymID string) ([]models.BadgeRule, error) {
+	if gymID == "" {
+		return nil, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.ListBadgeRules(ctx, gymID)
+}
+
+// CreateBadgeRule добавляет правило награды зала.
+// Уже заработанные по нему награды выдаются при следующем посещении или пересчете.
+func (s *Service) CreateBadgeRule(ctx context.Context, gymID string, rule models.BadgeRule) (models.BadgeRule, error) {
+	if gymID == "" {
+		return models.BadgeRule{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	rule.GymID = &gymID
+	rule.Code = strings.ToLower(strings.TrimSpace(rule.Code))
+	rule.Title = strings.TrimSpace(rule.Title)
+
+	if !badgeCodePattern.MatchString(rule.Code) {
+		return models.BadgeRule{}, fmt.Errorf("%w: код награды может содержать только латинские буквы, цифры и _", models.ErrInvalidArgument)
+	}
+	if rule.Title == "" {
+		return models.BadgeRule{}, fmt.Errorf("%w: требуется название награды", models.ErrInvalidArgument)
+	}
+	switch rule.Kind {
+	case models.VisitsBadge, models.StreakBadge:
+	default:
+		return models.BadgeRule{}, fmt.Errorf("%w: недопустимый вид награды", models.ErrInvalidArgument)
+	}
+	if rule.Threshold <= 0 {
+		return models.BadgeRule{}, fmt.Errorf("%w: порог награды должен быть положительным", models.ErrInvalidArgument)
+	}
+
+	return s.repo.CreateBadgeRule(ctx, rule)
+}
+
+// DeleteBadgeRule удаляет правило награды зала
+func (s *Service) DeleteBadgeRule(ctx context.Context, gymID, ruleID string) error {
+	if gymID == "" || ruleID == "" {
+		return fmt.Errorf("%w: требуются ID зала и ID правила", models.ErrInvalidArgument)
+	}
+
+	return s.repo.DeleteBadgeRule(ctx, gymID, ruleID)
+}
+
+// attachBadges добавляет участникам зала их награды
+func (s *Service) attachBadges(ctx context.Context, gymID string, users []models.User) ([]models.User, error) {
+	if len(users) == 0 {
+		return users, nil
+	}
+
+	badges, err := s.repo.ListMemberBadges(ctx, gymID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[string][]models.Badge)
+	for _, badge := range badges {
+		byUser[badge.UserID] = append(byUser[badge.UserID], badge)
+	}
+	for i := range users {
+		users[i].Badges = byUser[users[i].ID]
+	}
+
+	return users, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"myapp/internal/models"
+)
+
+const (
+	defaultBuddyWeeks = 8
+	maxBuddyWeeks     = 26
+	buddiesLimit      = 10
+)
+
+// GetBuddySuggestions подбирает вызывающему участников, которые за последние weeks недель
+// ходили в зал в похожие дни и часы. Подбор доступен только включившим его участникам.
+func (s *Service) GetBuddySuggestions(ctx context.Context, actor models.Actor, gymID string, weeks int) (models.BuddiesResponse, error) {
+	if gymID == "" {
+		return models.BuddiesResponse{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if weeks <= 0 || weeks > maxBuddyWeeks {
+		weeks = defaultBuddyWeeks
+	}
+	if err := s.requireMember(ctx, actor, gymID, false); err != nil {
+		return models.BuddiesResponse{}, err
+	}
+
+	prefs, err := s.repo.GetBuddyPreferences(ctx, gymID, actor.UserID)
+	if err != nil {
+		return models.BuddiesResponse{}, err
+	}
+	if !prefs.OptedIn {
+		return models.BuddiesResponse{}, fmt.Errorf("%w: сначала включите подбор партнеров", models.ErrForbidden)
+	}
+
+	gym, err := s.repo.GetGym(ctx, gymID)
+	if err != nil {
+		return models.BuddiesResponse{}, err
+	}
+
+	since := time.Now().AddDate(0, 0, -7*weeks)
+	buddies, err := s.repo.ListBuddySuggestions(ctx, gymID, actor.UserID, gym.Timezone, since, buddiesLimit)
+	if err != nil {
+		return models.BuddiesResponse{}, err
+	}
+
+	return models.BuddiesResponse{Weeks: weeks, Buddies: buddies}, nil
+}
+
+// GetBuddyPreferences возвращает настройки подбора партнеров вызывающего
+func (s *Service) GetBuddyPreferences(ctx context.Context, actor models.Actor, gymID string) (models.BuddyPreferences, error) {
+	if gymID == "" {
+		return models.BuddyPreferences{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.GetBuddyPreferences(ctx, gymID, actor.UserID)
+}
+
+// UpdateBuddyPreferences сохраняет настройки подбора партнеров вызывающего
+func (s *Service) UpdateBuddyPreferences(ctx context.Context, actor models.Actor, gymID string, prefs models.BuddyPreferences) (models.BuddyPreferences, error) {
+	if gymID == "" {
+		return models.BuddyPreferences{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if err := s.requireMember(ctx, actor, gymID, false); err != nil {
+		return models.BuddyPreferences{}, err
+	}
+
+	return s.repo.UpdateBuddyPreferences(ctx, gymID, actor.UserID, prefs)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"myapp/internal/models"
+	"myapp/internal/schedule"
+)
+
+// MaxScheduleRange - максимальная длина периода, за который строится расписание
+const MaxScheduleRange = 31 * 24 * time.Hour
+
+// CreateClass создает занятие зала
+func (s *Service) CreateClass(ctx context.Context, gymID, createdBy string, req models.ClassRequest) (models.Class, error) {
+	if gymID == "" {
+		return models.Class{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	class := models.Class{GymID: gymID, CreatedBy: createdBy}
+	if err := applyClassRequest(&class, req); err != nil {
+		return models.Class{}, err
+	}
+
+	return s.repo.CreateClass(ctx, class)
+}
+
+// ListClasses возвращает занятия зала
+func (s *Service) ListClasses(ctx context.Context, gymID string) ([]models.Class, error) {
+	if gymID == "" {
+		return nil, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.ListClasses(ctx, gymID)
+}
+
+// UpdateClass изменяет занятие зала
+func (s *Service) UpdateClass(ctx context.Context, gymID, classID string, req models.ClassRequest) (models.Class, error) {
+	if gymID == "" || classID == "" {
+		return models.Class{}, fmt.Errorf("%w: требуются ID зала и ID занятия", models.ErrInvalidArgument)
+	}
+
+	class := models.Class{ID: classID, GymID: gymID}
+	if err := applyClassRequest(&class, req); err != nil {
+		return models.Class{}, err
+	}
+
+	return s.repo.UpdateClass(ctx, class)
+}
+
+// DeleteClass удаляет занятие зала
+func (s *Service) DeleteClass(ctx context.Context, gymID, classID string) error {
+	if gymID == "" || classID == "" {
+		return fmt.Errorf("%w: требуются ID зала и ID занятия", models.ErrInvalidArgument)
+	}
+
+	return s.repo.DeleteClass(ctx, gymID, classID)
+}
+
+// GetSchedule возвращает занятия зала, начинающиеся в [from, to), с числом записей
+// и записью вызывающего. Даты повторяющихся занятий вычисляются в часовом поясе зала.
+func (s *Service) GetSchedule(ctx context.Context, actor models.Actor, gymID string, from, to time.Time) (models.Schedule, error) {
+	if gymID == "" {
+		return models.Schedule{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if !to.After(from) || to.Sub(from) > MaxScheduleRange {
+		return models.Schedule{}, fmt.Errorf("%w: период расписания должен быть не длиннее 31 дня", models.ErrInvalidArgument)
+	}
+	if err := s.requireMember(ctx, actor, gymID, false); err != nil {
+		return models.Schedule{}, err
+	}
+
+	loc, err := s.gymLocation(ctx, gymID)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	classes, err := s.repo.ListClasses(ctx, gymID)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	counts, err := s.repo.ListSessionCounts(ctx, gymID, from, to, actor.UserID)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	type sessionKey struct {
+		classID  string
+		startsAt int64
+	}
+	bySession := make(map[sessionKey]models.SessionCounts, len(counts))
+	for _, c := range counts {
+		bySession[sessionKey{c.ClassID, c.StartsAt.Unix()}] = c
+	}
+
+	result := models.Schedule{GymID: gymID, Timezone: loc.String(), From: from, To: to, Occurrences: []models.ClassOccurrence{}}
+	for _, class := range classes {
+		rule, start, err := classSeries(class, loc)
+		if err != nil {
+			return models.Schedule{}, err
+		}
+
+		for _, startsAt := range rule.Occurrences(start, from, to) {
+			occurrence := models.ClassOccurrence{
+				ClassID:  class.ID,
+				Title:    class.Title,
+				StartsAt: startsAt,
+				EndsAt:   startsAt.Add(time.Duration(class.DurationMinutes) * time.Minute),
+				Capacity: class.Capacity,
+			}
+			if c, ok := bySession[sessionKey{class.ID, startsAt.Unix()}]; ok {
+				occurrence.Booked = c.Booked
+				occurrence.Waitlisted = c.Waitlisted
+				if c.MyStatus != nil {
+					occurrence.MyBooking = *c.MyStatus
+				}
+			}
+			result.Occurrences = append(result.Occurrences, occurrence)
+		}
+	}
+
+	sort.SliceStable(result.Occurrences, func(i, j int) bool {
+		return result.Occurrences[i].StartsAt.Before(result.Occurrences[j].StartsAt)
+	})
+
+	return result, nil
+}
+
+// BookClass записывает вызывающего на занятие серии, начинающееся в startsAt.
+// Если мест нет, вызывающий попадает в лист ожидания.
+func (s *Service) BookClass(ctx context.Context, actor models.Actor, gymID, classID string, startsAt time.Time) (models.Booking, error) {
+	if err := s.requireMember(ctx, actor, gymID, true); err != nil {
+		return models.Booking{}, err
+	}
+	if _, err := s.classSession(ctx, gymID, classID, startsAt); err != nil {
+		return models.Booking{}, err
+	}
+	if !startsAt.After(time.Now()) {
+		return models.Booking{}, fmt.Errorf("%w: занятие уже началось", models.ErrInvalidArgument)
+	}
+
+	return s.repo.BookSession(ctx, classID, startsAt, actor.UserID)
+}
+
+// CancelBooking отменяет запись вызывающего на занятие серии, начинающееся в startsAt.
+// Освободившееся место получает первый участник из листа ожидания.
+func (s *Service) CancelBooking(ctx context.Context, actor models.Actor, gymID, classID string, startsAt time.Time) (models.Booking, error) {
+	if _, err := s.classSession(ctx, gymID, classID, startsAt); err != nil {
+		return models.Booking{}, err
+	}
+
+	return s.repo.CancelBooking(ctx, classID, startsAt, actor.UserID)
+}
+
+// classSession проверяет, что startsAt - начало одного из занятий серии
+func (s *Service) classSession(ctx context.Context, gymID, classID string, startsAt time.Time) (models.Class, error) {
+	if gymID == "" || classID == "" {
+		return models.Class{}, fmt.Errorf("%w: требуются ID зала и ID занятия", models.ErrInvalidArgument)
+	}
+	if startsAt.IsZero() {
+		return models.Class{}, fmt.Errorf("%w: требуется время начала занятия", models.ErrInvalidArgument)
+	}
+
+	class, err := s.repo.GetClass(ctx, gymID, classID)
+	if err != nil {
+		return models.Class{}, err
+	}
+
+	loc, err := s.gymLocation(ctx, gymID)
+	if err != nil {
+		return models.Class{}, err
+	}
+
+	rule, start, err := classSeries(class, loc)
+	if err != nil {
+		return models.Class{}, err
+	}
+	if !rule.Includes(start, startsAt) {
+		return models.Class{}, fmt.Errorf("%w: в это время занятия нет", models.ErrNotFound)
+	}
+
+	return class, nil
+}
+
+// gymLocation возвращает часовой пояс зала
+func (s *Service) gymLocation(ctx context.Context, gymID string) (*time.Location, error) {
+	gym, err := s.repo.GetGym(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	return time.LoadLocation(gym.Timezone)
+}
+
+// classSeries возвращает правило повторения занятия и начало серии в часовом поясе зала
+func classSeries(class models.Class, loc *time.Location) (schedule.Rule, time.Time, error) {
+	rule, err := schedule.ParseRule(class.RRule)
+	if err != nil {
+		return schedule.Rule{}, time.Time{}, fmt.Errorf("правило повторения занятия %s: %w", class.ID, err)
+	}
+
+	start, err := time.ParseInLocation(models.LocalTimeLayout, class.StartsAt, loc)
+	if err != nil {
+		return schedule.Rule{}, time.Time{}, fmt.Errorf("начало занятия %s: %w", class.ID, err)
+	}
+
+	return rule, start, nil
+}
+
+// applyClassRequest проверяет запрос и переносит его в занятие
+func applyClassRequest(class *models.Class, req models.ClassRequest) error {
+	class.Title = strings.TrimSpace(req.Title)
+	class.Description = strings.TrimSpace(req.Description)
+	class.StartsAt = strings.TrimSpace(req.StartsAt)
+	class.RRule = strings.TrimPrefix(strings.TrimSpace(req.RRule), "RRULE:")
+	class.DurationMinutes = req.DurationMinutes
+	class.Capacity = req.Capacity
+
+	if class.Title == "" {
+		return fmt.Errorf("%w: требуется название занятия", models.ErrInvalidArgument)
+	}
+	if _, err := time.Parse(models.LocalTimeLayout, class.StartsAt); err != nil {
+		return fmt.Errorf("%w: время начала занятия должно быть в формате %s", models.ErrInvalidArgument, models.LocalTimeLayout)
+	}
+	if class.DurationMinutes <= 0 {
+		return fmt.Errorf("%w: длительность занятия должна быть положительной", models.ErrInvalidArgument)
+	}
+	if class.Capacity <= 0 {
+		return fmt.Errorf("%w: вместимость занятия должна быть положительной", models.ErrInvalidArgument)
+	}
+	if _, err := schedule.ParseRule(class.RRule); err != nil {
+		return fmt.Errorf("%w: правило повторения: %v", models.ErrInvalidArgument, err)
+	}
+
+	return nil
+}
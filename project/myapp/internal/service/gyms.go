@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"myapp/internal/audit"
+	"myapp/internal/models"
+	"myapp/internal/tenant"
+)
+
+// openingTimeLayout - формат времени открытия и закрытия зала
+const openingTimeLayout = "15:04"
+
+// CreateGym создает зал. Зал принадлежит организации вызывающего; организацию
+// из запроса учитывает только администратор платформы.
+func (s *Service) CreateGym(ctx context.Context, req models.GymRequest) (models.Gym, error) {
+	gym := models.Gym{OrganizationID: tenant.OrganizationID(ctx)}
+	if gym.OrganizationID == "" {
+		gym.OrganizationID = strings.TrimSpace(req.OrganizationID)
+	}
+	if gym.OrganizationID == "" {
+		return models.Gym{}, fmt.Errorf("%w: требуется ID организации", models.ErrInvalidArgument)
+	}
+	if err := applyGymRequest(&gym, req); err != nil {
+		return models.Gym{}, err
+	}
+
+	return s.repo.CreateGym(ctx, gym)
+}
+
+// GetGym возвращает зал по ID
+func (s *Service) GetGym(ctx context.Context, gymID string) (models.Gym, error) {
+	if gymID == "" {
+		return models.Gym{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.GetGym(ctx, gymID)
+}
+
+// ListGyms возвращает все залы
+func (s *Service) ListGyms(ctx context.Context) ([]models.Gym, error) {
+	return s.repo.ListGyms(ctx)
+}
+
+// UpdateGym изменяет зал
+func (s *Service) UpdateGym(ctx context.Context, gymID string, req models.GymRequest) (models.Gym, error) {
+	if gymID == "" {
+		return models.Gym{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	gym := models.Gym{ID: gymID}
+	if err := applyGymRequest(&gym, req); err != nil {
+		return models.Gym{}, err
+	}
+
+	before, err := s.repo.GetGym(ctx, gymID)
+	if err != nil {
+		return models.Gym{}, err
+	}
+	updated, err := s.repo.UpdateGym(ctx, gym)
+	if err != nil {
+		return models.Gym{}, err
+	}
+
+	audit.RecordChange(ctx, models.AuditChange{GymID: gymID, Before: before, After: updated})
+	return updated, nil
+}
+
+// DeleteGym удаляет зал без участников
+func (s *Service) DeleteGym(ctx context.Context, gymID string) error {
+	if gymID == "" {
+		return fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.DeleteGym(ctx, gymID)
+}
+
+// applyGymRequest проверяет запрос и переносит его в зал
+func applyGymRequest(gym *models.Gym, req models.GymRequest) error {
+	gym.Name = strings.TrimSpace(req.Name)
+	gym.Address = strings.TrimSpace(req.Address)
+	gym.Latitude = req.Latitude
+	gym.Longitude = req.Longitude
+	gym.Timezone = strings.TrimSpace(req.Timezone)
+	gym.OpeningHours = req.OpeningHours
+
+	if gym.Name == "" {
+		return fmt.Errorf("%w: требуется название зала", models.ErrInvalidArgument)
+	}
+	if gym.Timezone == "" {
+		gym.Timezone = models.DefaultTimezone
+	}
+	if _, err := time.LoadLocation(gym.Timezone); err != nil {
+		return fmt.Errorf("%w: неизвестный часовой пояс", models.ErrInvalidArgument)
+	}
+	if (gym.Latitude == nil) != (gym.Longitude == nil) {
+		return fmt.Errorf("%w: широта и долгота задаются вместе", models.ErrInvalidArgument)
+	}
+	if gym.Latitude != nil && (*gym.Latitude < -90 || *gym.Latitude > 90) {
+		return fmt.Errorf("%w: широта должна быть от -90 до 90", models.ErrInvalidArgument)
+	}
+	if gym.Longitude != nil && (*gym.Longitude < -180 || *gym.Longitude > 180) {
+		return fmt.Errorf("%w: долгота должна быть от -180 до 180", models.ErrInvalidArgument)
+	}
+	if gym.OpeningHours == nil {
+		gym.OpeningHours = models.OpeningHours{}
+	}
+
+	return checkOpeningHours(gym.OpeningHours)
+}
+
+// checkOpeningHours проверяет дни недели и время в часах работы
+func checkOpeningHours(hours models.OpeningHours) error {
+	for i, period := range hours {
+		period.Weekday = strings.ToLower(strings.TrimSpace(period.Weekday))
+		if !isWeekday(period.Weekday) {
+			return fmt.Errorf("%w: день недели должен быть одним из %s", models.ErrInvalidArgument, strings.Join(models.Weekdays, ", "))
+		}
+
+		opens, err := time.Parse(openingTimeLayout, period.Opens)
+		if err != nil {
+			return fmt.Errorf("%w: время открытия должно быть в формате %s", models.ErrInvalidArgument, openingTimeLayout)
+		}
+		closes, err := time.Parse(openingTimeLayout, period.Closes)
+		if err != nil {
+			return fmt.Errorf("%w: время закрытия должно быть в формате %s", models.ErrInvalidArgument, openingTimeLayout)
+		}
+		if opens.Equal(closes) {
+			return fmt.Errorf("%w: время открытия и закрытия совпадают", models.ErrInvalidArgument)
+		}
+
+		hours[i] = period
+	}
+
+	return nil
+}
+
+// isWeekday сообщает, является ли значение днем недели часов работы
+func isWeekday(value string) bool {
+	for _, weekday := range models.Weekdays {
+		if weekday == value {
+			return true
+		}
+	}
+	return false
+}
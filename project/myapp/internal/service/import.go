@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"myapp/internal/models"
+)
+
+// MaxImportRows - максимальное количество строк в одном импорте
+const MaxImportRows = 5000
+
+// uuidPattern проверяет формат UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ImportMembers импортирует пользователей и их статусы в зал от имени администратора actor.
+// Строки с ошибками проверки не отправляются в базу и отмечаются в отчете.
+func (s *Service) ImportMembers(ctx context.Context, actor models.Actor, gymID string, rows []models.ImportMemberRow, dryRun bool) (models.ImportReport, error) {
+	report := models.ImportReport{DryRun: dryRun, Rows: []models.ImportRowReport{}}
+
+	if gymID == "" {
+		return report, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if len(rows) == 0 {
+		return report, fmt.Errorf("%w: нет строк для импорта", models.ErrInvalidArgument)
+	}
+	if len(rows) > MaxImportRows {
+		return report, fmt.Errorf("%w: не более %d строк за один импорт", models.ErrInvalidArgument, MaxImportRows)
+	}
+
+	// Проверяем строки и запоминаем исходные номера допустимых
+	reports := make([]models.ImportRowReport, len(rows))
+	var valid []models.ImportMemberRow
+	var positions []int
+	for i, row := range rows {
+		row = normalizeImportRow(row)
+		if err := validateImportRow(row); err != nil {
+			reports[i] = models.ImportRowReport{
+				Row:    i + 1,
+				UserID: row.ID,
+				Email:  row.Email,
+				Result: models.ImportFailed,
+				Error:  err.Error(),
+			}
+			continue
+		}
+		valid = append(valid, row)
+		positions = append(positions, i)
+	}
+
+	if len(valid) > 0 {
+		// Импорт выполняет администратор, поэтому статусы меняются по его правам
+		check := func(from, to models.ActivityStatus) error {
+			return checkTransition(from, to, actorAdmin, "")
+		}
+		results, err := s.repo.ImportMembers(ctx, actor, gymID, valid, dryRun, check)
+		if err != nil {
+			return report, err
+		}
+		for j, result := range results {
+			result.Row = positions[j] + 1
+			reports[positions[j]] = result
+		}
+	}
+
+	for _, r := range reports {
+		report.Add(r)
+	}
+
+	return report, nil
+}
+
+// ExportMembers передает участников зала в fn по одному
+func (s *Service) ExportMembers(ctx context.Context, gymID string, fn func(models.User) error) error {
+	if gymID == "" {
+		return fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.StreamGroupMembers(ctx, gymID, fn)
+}
+
+// normalizeImportRow убирает пробелы и подставляет статус по умолчанию
+func normalizeImportRow(row models.ImportMemberRow) models.ImportMemberRow {
+	row.ID = strings.ToLower(strings.TrimSpace(row.ID))
+	row.Email = strings.TrimSpace(row.Email)
+	row.FirstName = strings.TrimSpace(row.FirstName)
+	row.LastName = strings.TrimSpace(row.LastName)
+	row.Status = models.ActivityStatus(strings.ToLower(strings.TrimSpace(string(row.Status))))
+	if row.Status == "" {
+		row.Status = models.ActiveStatus
+	}
+	return row
+}
+
+// validateImportRow проверяет строку импорта
+func validateImportRow(row models.ImportMemberRow) error {
+	if !uuidPattern.MatchString(row.ID) {
+		return errors.New("недопустимый ID пользователя")
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		return errors.New("недопустимый email")
+	}
+	if row.FirstName == "" || row.LastName == "" {
+		return errors.New("требуются имя и фамилия")
+	}
+	if row.Status != models.ActiveStatus && row.Status != models.InactiveStatus {
+		return errors.New("недопустимое значение статуса")
+	}
+	return nil
+}
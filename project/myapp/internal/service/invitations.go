@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"myapp/internal/audit"
+	"myapp/internal/models"
+)
+
+// invitationCodeEncoding кодирует коды приглашений без символов, которые легко перепутать
+var invitationCodeEncoding = base32.NewEncoding("ABCDEFGHJKLMNPQRSTUVWXYZ23456789").WithPadding(base32.NoPadding)
+
+// CreateInvitation создает приглашение в зал от имени администратора или сервиса actor
+func (s *Service) CreateInvitation(ctx context.Context, actor models.Actor, gymID string, req models.CreateInvitationRequest) (models.Invitation, error) {
+	if gymID == "" || actor.UserID == "" {
+		return models.Invitation{}, fmt.Errorf("%w: требуются ID зала и ID создателя", models.ErrInvalidArgument)
+	}
+	if req.MaxUses != nil && *req.MaxUses < 1 {
+		return models.Invitation{}, fmt.Errorf("%w: max_uses должен быть положительным", models.ErrInvalidArgument)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return models.Invitation{}, fmt.Errorf("%w: expires_at должен быть в будущем", models.ErrInvalidArgument)
+	}
+
+	invitation := models.Invitation{
+		GymID:         gymID,
+		MaxUses:       req.MaxUses,
+		ExpiresAt:     req.ExpiresAt,
+		CreatedBy:     actor.UserID,
+		CreatedByRole: &actor.Role,
+	}
+
+	if email := strings.TrimSpace(req.Email); email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return models.Invitation{}, fmt.Errorf("%w: недопустимый email", models.ErrInvalidArgument)
+		}
+		invitation.Email = &email
+	}
+
+	code, err := generateInvitationCode()
+	if err != nil {
+		return models.Invitation{}, err
+	}
+	invitation.Code = code
+
+	return s.repo.CreateInvitation(ctx, invitation)
+}
+
+// ListInvitations получает приглашения зала
+func (s *Service) ListInvitations(ctx context.Context, gymID string) ([]models.Invitation, error) {
+	if gymID == "" {
+		return nil, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.ListInvitations(ctx, gymID)
+}
+
+// RevokeInvitation отзывает приглашение зала
+func (s *Service) RevokeInvitation(ctx context.Context, gymID, invitationID string) (models.Invitation, error) {
+	if gymID == "" || invitationID == "" {
+		return models.Invitation{}, fmt.Errorf("%w: требуются ID зала и ID приглашения", models.ErrInvalidArgument)
+	}
+
+	return s.repo.RevokeInvitation(ctx, gymID, invitationID)
+}
+
+// RedeemInvitation погашает приглашение и добавляет вызывающего пользователя в зал
+func (s *Service) RedeemInvitation(ctx context.Context, actor models.Actor, code string) (models.Invitation, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" || actor.UserID == "" {
+		return models.Invitation{}, fmt.Errorf("%w: требуются код приглашения и ID пользователя", models.ErrInvalidArgument)
+	}
+
+	return s.repo.RedeemInvitation(ctx, actor, code, checkInvitation)
+}
+
+// checkInvitation проверяет, что приглашение действует и подходит пользователю с email
+func checkInvitation(invitation models.Invitation, email string) error {
+	switch {
+	case invitation.RevokedAt != nil:
+		return fmt.Errorf("%w: приглашение отозвано", models.ErrForbidden)
+	case invitation.ExpiresAt != nil && time.Now().After(*invitation.ExpiresAt):
+		return fmt.Errorf("%w: срок действия приглашения истек", models.ErrForbidden)
+	case invitation.MaxUses != nil && invitation.Uses >= *invitation.MaxUses:
+		return fmt.Errorf("%w: приглашение уже использовано максимальное число раз", models.ErrForbidden)
+	case invitation.Email != nil && !strings.EqualFold(*invitation.Email, email):
+		return fmt.Errorf("%w: приглашение выдано для другого email", models.ErrForbidden)
+	}
+	return nil
+}
+
+// GetGymSettings получает настройки зала
+func (s *Service) GetGymSettings(ctx context.Context, gymID string) (models.GymSettings, error) {
+	if gymID == "" {
+		return models.GymSettings{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.GetGymSettings(ctx, gymID)
+}
+
+// UpdateGymSettings сохраняет настройки зала
+func (s *Service) UpdateGymSettings(ctx context.Context, settings models.GymSettings) (models.GymSettings, error) {
+	if settings.GymID == "" {
+		return models.GymSettings{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	switch settings.JoinPolicy {
+	case models.OpenPolicy, models.ApprovalPolicy, models.InviteOnlyPolicy:
+	default:
+		return models.GymSettings{}, fmt.Errorf("%w: недопустимое правило вступления", models.ErrInvalidArgument)
+	}
+
+	current, err := s.repo.GetGymSettings(ctx, settings.GymID)
+	if err != nil {
+		return models.GymSettings{}, err
+	}
+
+	// Порог серии не меняется, если не указан в запросе
+	if settings.StreakMinVisits == 0 {
+		settings.StreakMinVisits = current.StreakMinVisits
+	}
+	if settings.StreakMinVisits < 1 || settings.StreakMinVisits > 14 {
+		return models.GymSettings{}, fmt.Errorf("%w: для серии нужно от 1 до 14 посещений в неделю", models.ErrInvalidArgument)
+	}
+
+	updated, err := s.repo.UpdateGymSettings(ctx, settings)
+	if err != nil {
+		return models.GymSettings{}, err
+	}
+
+	audit.RecordChange(ctx, models.AuditChange{GymID: settings.GymID, Before: current, After: updated})
+	return updated, nil
+}
+
+// generateInvitationCode генерирует случайный код приглашения
+func generateInvitationCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return invitationCodeEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"myapp/internal/models"
+)
+
+// ListJoinRequests получает заявки зала, ожидающие одобрения
+func (s *Service) ListJoinRequests(ctx context.Context, gymID string) ([]models.JoinRequest, error) {
+	if gymID == "" {
+		return nil, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.ListJoinRequests(ctx, gymID)
+}
+
+// ApproveJoinRequest одобряет заявку: пользователь становится активным участником
+func (s *Service) ApproveJoinRequest(ctx context.Context, reviewer models.Actor, userID, gymID, reason string) error {
+	if userID == "" || gymID == "" || reviewer.UserID == "" {
+		return fmt.Errorf("%w: требуются ID пользователя, ID зала и ID администратора", models.ErrInvalidArgument)
+	}
+
+	return s.reviewJoinRequest(ctx, reviewer, userID, gymID, models.ActiveStatus, strings.TrimSpace(reason))
+}
+
+// RejectJoinRequest отклоняет заявку с указанием причины
+func (s *Service) RejectJoinRequest(ctx context.Context, reviewer models.Actor, userID, gymID, reason string) error {
+	if userID == "" || gymID == "" || reviewer.UserID == "" {
+		return fmt.Errorf("%w: требуются ID пользователя, ID зала и ID администратора", models.ErrInvalidArgument)
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("%w: требуется причина отклонения", models.ErrInvalidArgument)
+	}
+
+	return s.reviewJoinRequest(ctx, reviewer, userID, gymID, models.RejectedStatus, reason)
+}
+
+// reviewJoinRequest переводит заявку в статус решения администратора.
+// Сервис рассматривает заявку по своим правам машины состояний: одобрить может, отклонить - нет.
+func (s *Service) reviewJoinRequest(ctx context.Context, reviewer models.Actor, userID, gymID string, status models.ActivityStatus, reason string) error {
+	kind, err := resolveActor(reviewer, userID)
+	if err != nil {
+		return err
+	}
+
+	change := models.StatusChange{Status: status, Reason: reason, ChangedBy: reviewer.UserID, ChangedByRole: reviewer.Role}
+	_, err = s.repo.UpdateUserStatus(ctx, userID, gymID, change, func(from models.ActivityStatus) error {
+		if from != models.PendingStatus {
+			return fmt.Errorf("%w: заявка уже рассмотрена", models.ErrConflict)
+		}
+		return checkTransition(from, status, kind, reason)
+	})
+	return err
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"myapp/internal/models"
+)
+
+// actorKind определяет, в каком качестве вызывающая сторона меняет статус участника
+type actorKind string
+
+const (
+	actorSelf   actorKind = "self"   // сам участник
+	actorAdmin  actorKind = "admin"  // администратор
+	actorSystem actorKind = "system" // внутренний сервис
+	actorJoin   actorKind = "join"   // вступление по правилу зала (AddUserToGym)
+)
+
+// transition описывает разрешенный переход статуса участника
+type transition struct {
+	from           models.ActivityStatus
+	to             models.ActivityStatus
+	actors         []actorKind
+	requiresReason bool
+}
+
+// transitions - машина состояний участника зала.
+// Один и тот же переход может требовать причину от одной роли и не требовать от другой.
+// Набор пар (from, to) повторяется в триггере group_members_check_transition.
+var transitions = []transition{
+	// Рассмотрение заявки и ее отзыв
+	{models.PendingStatus, models.ActiveStatus, []actorKind{actorAdmin, actorSystem}, false},
+	{models.PendingStatus, models.RejectedStatus, []actorKind{actorAdmin}, true},
+	{models.PendingStatus, models.LeftStatus, []actorKind{actorSelf}, false},
+
+	// Активность
+	{models.ActiveStatus, models.InactiveStatus, []actorKind{actorSelf, actorAdmin, actorSystem}, false},
+	{models.InactiveStatus, models.ActiveStatus, []actorKind{actorSelf, actorAdmin, actorSystem}, false},
+
+	// Приостановка участия
+	{models.ActiveStatus, models.SuspendedStatus, []actorKind{actorAdmin}, true},
+	{models.InactiveStatus, models.SuspendedStatus, []actorKind{actorAdmin}, true},
+	{models.SuspendedStatus, models.ActiveStatus, []actorKind{actorAdmin}, false},
+
+	// Выход из зала: сам участник уходит без объяснений, администратор исключает с причиной
+	{models.ActiveStatus, models.LeftStatus, []actorKind{actorSelf}, false},
+	{models.ActiveStatus, models.LeftStatus, []actorKind{actorAdmin}, true},
+	{models.InactiveStatus, models.LeftStatus, []actorKind{actorSelf}, false},
+	{models.InactiveStatus, models.LeftStatus, []actorKind{actorAdmin}, true},
+	{models.SuspendedStatus, models.LeftStatus, []actorKind{actorSelf}, false},
+	{models.SuspendedStatus, models.LeftStatus, []actorKind{actorAdmin}, true},
+
+	// Блокировка и ее снятие
+	{models.PendingStatus, models.BannedStatus, []actorKind{actorAdmin}, true},
+	{models.ActiveStatus, models.BannedStatus, []actorKind{actorAdmin}, true},
+	{models.InactiveStatus, models.BannedStatus, []actorKind{actorAdmin}, true},
+	{models.SuspendedStatus, models.BannedStatus, []actorKind{actorAdmin}, true},
+	{models.BannedStatus, models.LeftStatus, []actorKind{actorAdmin}, true},
+
+	// После отклонения администратор может разрешить подать заявку снова
+	{models.RejectedStatus, models.LeftStatus, []actorKind{actorAdmin}, false},
+
+	// Повторное вступление. Сам участник возвращается только через вступление
+	// или приглашение, которые учитывают правило вступления зала.
+	{models.LeftStatus, models.ActiveStatus, []actorKind{actorJoin, actorAdmin, actorSystem}, false},
+	{models.LeftStatus, models.PendingStatus, []actorKind{actorJoin}, false},
+}
+
+// allows сообщает, может ли actor выполнить переход
+func (t transition) allows(actor actorKind) bool {
+	for _, a := range t.actors {
+		if a == actor {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnownStatus сообщает, известен ли статус машине состояний
+func isKnownStatus(status models.ActivityStatus) bool {
+	for _, t := range transitions {
+		if t.from == status || t.to == status {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition проверяет, может ли actor перевести участника из from в to
+func checkTransition(from, to models.ActivityStatus, actor actorKind, reason string) error {
+	if !isKnownStatus(to) {
+		return fmt.Errorf("%w: недопустимое значение статуса", models.ErrInvalidArgument)
+	}
+
+	exists := false
+	for _, t := range transitions {
+		if t.from != from || t.to != to {
+			continue
+		}
+		exists = true
+		if !t.allows(actor) {
+			continue
+		}
+		if t.requiresReason && reason == "" {
+			return fmt.Errorf("%w: для перехода из %s в %s требуется причина", models.ErrInvalidArgument, from, to)
+		}
+		return nil
+	}
+
+	if exists {
+		return fmt.Errorf("%w: недостаточно прав для перехода из %s в %s", models.ErrForbidden, from, to)
+	}
+	return fmt.Errorf("%w: переход из %s в %s не допускается", models.ErrConflict, from, to)
+}
+
+// nextTransitions возвращает статусы, в которые actor может перевести участника из from
+func nextTransitions(from models.ActivityStatus, actor actorKind) []models.StatusTransition {
+	next := []models.StatusTransition{}
+	for _, t := range transitions {
+		if t.from == from && t.allows(actor) {
+			next = append(next, models.StatusTransition{Status: t.to, RequiresReason: t.requiresReason})
+		}
+	}
+	return next
+}
+
+// resolveActor определяет, в каком качестве actor меняет статус пользователя userID
+func resolveActor(actor models.Actor, userID string) (actorKind, error) {
+	switch {
+	case actor.Role == models.RoleAdmin || actor.Role == models.RolePlatformAdmin:
+		return actorAdmin, nil
+	case actor.Role == models.RoleService:
+		return actorSystem, nil
+	case actor.UserID != "" && actor.UserID == userID:
+		return actorSelf, nil
+	default:
+		return "", fmt.Errorf("%w: можно изменять только собственный статус", models.ErrForbidden)
+	}
+}
+
+// AllowedTransitions возвращает текущий статус участника и статусы, в которые actor может его перевести
+func (s *Service) AllowedTransitions(ctx context.Context, actor models.Actor, userID, gymID string) (models.AllowedTransitionsResponse, error) {
+	if userID == "" || gymID == "" {
+		return models.AllowedTransitionsResponse{}, fmt.Errorf("%w: требуются ID пользователя и ID зала", models.ErrInvalidArgument)
+	}
+
+	kind, err := resolveActor(actor, userID)
+	if err != nil {
+		return models.AllowedTransitionsResponse{}, err
+	}
+
+	status, err := s.repo.GetUserStatus(ctx, userID, gymID)
+	if err != nil {
+		return models.AllowedTransitionsResponse{}, err
+	}
+
+	return models.AllowedTransitionsResponse{
+		Status:      status,
+		Transitions: nextTransitions(status, kind),
+	}, nil
+}
+
+// ListStatusHistory возвращает страницу истории статусов участника зала от новых записей к старым.
+// Историю видят сам участник и администраторы.
+func (s *Service) ListStatusHistory(ctx context.Context, actor models.Actor, gymID, userID, cursor string, limit int) (models.StatusHistoryPage, error) {
+	if userID == "" || gymID == "" {
+		return models.StatusHistoryPage{}, fmt.Errorf("%w: требуются ID пользователя и ID зала", models.ErrInvalidArgument)
+	}
+	if !isPrivileged(actor) && actor.UserID != userID {
+		return models.StatusHistoryPage{}, fmt.Errorf("%w: можно просматривать только собственную историю статусов", models.ErrForbidden)
+	}
+
+	scope := historyScope(gymID, userID)
+	query, err := s.pageQuery(scope, cursor, limit, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		return models.StatusHistoryPage{}, err
+	}
+
+	entries, err := s.repo.ListStatusHistory(ctx, gymID, userID, query)
+	if err != nil {
+		return models.StatusHistoryPage{}, err
+	}
+
+	page := models.StatusHistoryPage{}
+	page.Entries, page.NextCursor, page.PrevCursor = keysetPage(s, scope, query, entries, func(e models.StatusHistoryEntry) models.PageKey {
+		return models.PageKey{Time: e.ChangedAt, ID: strconv.FormatInt(e.ID, 10)}
+	})
+
+	return page, nil
+}
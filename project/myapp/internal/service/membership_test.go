@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"myapp/internal/models"
+)
+
+const (
+	testGym  = "gym-1"
+	testUser = "user-1"
+)
+
+// memberRepository - репозиторий с одним участником зала и правилом вступления зала
+type memberRepository struct {
+	Repository
+
+	policy models.JoinPolicy
+	status models.ActivityStatus
+}
+
+func (f *memberRepository) GetGymSettings(ctx context.Context, gymID string) (models.GymSettings, error) {
+	return models.GymSettings{GymID: gymID, JoinPolicy: f.policy}, nil
+}
+
+func (f *memberRepository) GetUserStatus(ctx context.Context, userID, gymID string) (models.ActivityStatus, error) {
+	return f.status, nil
+}
+
+func (f *memberRepository) AddUserToGym(ctx context.Context, actor models.Actor, userID, gymID string, status models.ActivityStatus) error {
+	f.status = status
+	return nil
+}
+
+func (f *memberRepository) UpdateUserStatus(ctx context.Context, userID, gymID string, change models.StatusChange, check func(models.ActivityStatus) error) (models.MemberStatus, error) {
+	if err := check(f.status); err != nil {
+		return models.MemberStatus{}, err
+	}
+	f.status = change.Status
+	return models.MemberStatus{Status: change.Status}, nil
+}
+
+func TestRejoinFollowsJoinPolicy(t *testing.T) {
+	self := models.Actor{UserID: testUser, Role: models.RoleUser}
+
+	tests := []struct {
+		policy  models.JoinPolicy
+		want    models.ActivityStatus
+		wantErr error
+	}{
+		{models.OpenPolicy, models.ActiveStatus, nil},
+		{models.ApprovalPolicy, models.PendingStatus, nil},
+		{models.InviteOnlyPolicy, models.LeftStatus, models.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			repo := &memberRepository{policy: tt.policy, status: models.LeftStatus}
+			s := NewService(repo, "secret")
+
+			// Вышедший участник не может вернуть себе статус active в обход правила вступления
+			_, err := s.UpdateUserStatus(context.Background(), self, testUser, testGym, models.ActiveStatus, "", nil)
+			if !errors.Is(err, models.ErrForbidden) {
+				t.Errorf("UpdateUserStatus: ожидалась ошибка ErrForbidden, получено %v", err)
+			}
+			_, err = s.UpdateUserStatus(context.Background(), self, testUser, testGym, models.PendingStatus, "", nil)
+			if !errors.Is(err, models.ErrForbidden) {
+				t.Errorf("UpdateUserStatus в pending: ожидалась ошибка ErrForbidden, получено %v", err)
+			}
+
+			status, err := s.AddUserToGym(context.Background(), self, testUser, testGym)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddUserToGym: ожидалась ошибка %v, получено %v", tt.wantErr, err)
+			}
+			if repo.status != tt.want {
+				t.Errorf("AddUserToGym: ожидался статус %s, получен %s (%s)", tt.want, repo.status, status)
+			}
+		})
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    models.ActivityStatus
+		to      models.ActivityStatus
+		actor   actorKind
+		reason  string
+		wantErr error
+	}{
+		{"участник уходит на паузу", models.ActiveStatus, models.InactiveStatus, actorSelf, "", nil},
+		{"участник выходит сам без причины", models.ActiveStatus, models.LeftStatus, actorSelf, "", nil},
+		{"администратор исключает с причиной", models.ActiveStatus, models.LeftStatus, actorAdmin, "нарушение правил", nil},
+		{"администратор исключает без причины", models.ActiveStatus, models.LeftStatus, actorAdmin, "", models.ErrInvalidArgument},
+		{"администратор одобряет заявку", models.PendingStatus, models.ActiveStatus, actorAdmin, "", nil},
+		{"участник одобряет свою заявку", models.PendingStatus, models.ActiveStatus, actorSelf, "", models.ErrForbidden},
+		{"сервис не блокирует", models.ActiveStatus, models.BannedStatus, actorSystem, "спам", models.ErrForbidden},
+		{"вышедший участник возвращается сам", models.LeftStatus, models.ActiveStatus, actorSelf, "", models.ErrForbidden},
+		{"вступление по правилу зала", models.LeftStatus, models.PendingStatus, actorJoin, "", nil},
+		{"заблокированный не возвращается", models.BannedStatus, models.ActiveStatus, actorAdmin, "", models.ErrConflict},
+		{"переход в тот же статус", models.ActiveStatus, models.ActiveStatus, actorAdmin, "", models.ErrConflict},
+		{"неизвестный статус", models.ActiveStatus, "deleted", actorAdmin, "", models.ErrInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTransition(tt.from, tt.to, tt.actor, tt.reason)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("ожидался успех, получено %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ожидалась ошибка %v, получено %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNextTransitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  models.ActivityStatus
+		actor actorKind
+		want  []models.StatusTransition
+	}{
+		{"участник в активном статусе", models.ActiveStatus, actorSelf, []models.StatusTransition{
+			{Status: models.InactiveStatus},
+			{Status: models.LeftStatus},
+		}},
+		{"заявка для администратора", models.PendingStatus, actorAdmin, []models.StatusTransition{
+			{Status: models.ActiveStatus},
+			{Status: models.RejectedStatus, RequiresReason: true},
+			{Status: models.BannedStatus, RequiresReason: true},
+		}},
+		{"вышедший участник", models.LeftStatus, actorSelf, []models.StatusTransition{}},
+		{"заблокированный для сервиса", models.BannedStatus, actorSystem, []models.StatusTransition{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextTransitions(tt.from, tt.actor)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ожидалось %v, получено %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReviewJoinRequestByRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		reviewer models.Actor
+		reject   bool
+		wantErr  error
+	}{
+		{"администратор одобряет", models.Actor{UserID: "admin-1", Role: models.RoleAdmin}, false, nil},
+		{"администратор отклоняет", models.Actor{UserID: "admin-1", Role: models.RoleAdmin}, true, nil},
+		{"сервис одобряет", models.Actor{UserID: "service-1", Role: models.RoleService}, false, nil},
+		{"сервис не отклоняет", models.Actor{UserID: "service-1", Role: models.RoleService}, true, models.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memberRepository{status: models.PendingStatus}
+			s := NewService(repo, "secret")
+
+			var err error
+			if tt.reject {
+				err = s.RejectJoinRequest(context.Background(), tt.reviewer, testUser, testGym, "нет мест")
+			} else {
+				err = s.ApproveJoinRequest(context.Background(), tt.reviewer, testUser, testGym, "")
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("ожидался успех, получено %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ожидалась ошибка %v, получено %v", tt.wantErr, err)
+			}
+		})
+	}
+}
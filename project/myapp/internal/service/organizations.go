@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"myapp/internal/models"
+)
+
+// CreateOrganization создает организацию
+func (s *Service) CreateOrganization(ctx context.Context, req models.OrganizationRequest) (models.Organization, error) {
+	org := models.Organization{Name: strings.TrimSpace(req.Name)}
+	if org.Name == "" {
+		return models.Organization{}, fmt.Errorf("%w: требуется название организации", models.ErrInvalidArgument)
+	}
+
+	return s.repo.CreateOrganization(ctx, org)
+}
+
+// ListOrganizations возвращает организации, доступные вызывающему
+func (s *Service) ListOrganizations(ctx context.Context) ([]models.Organization, error) {
+	return s.repo.ListOrganizations(ctx)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+
+	"myapp/internal/models"
+	"myapp/internal/pagination"
+)
+
+// Лимиты страниц постраничных списков
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// pageQuery разбирает курсор списка scope в запрос страницы.
+// Недопустимый лимит заменяется на defaultLimit.
+func (s *Service) pageQuery(scope, cursor string, limit, defaultLimit, maxLimit int) (models.PageQuery, error) {
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	page := models.PageQuery{Limit: limit}
+	if cursor != "" {
+		decoded, err := s.cursors.Decode(cursor, scope)
+		if err != nil {
+			return models.PageQuery{}, err
+		}
+		page.After = &decoded.Key
+		page.Backward = decoded.Backward
+	}
+
+	return page, nil
+}
+
+// keysetPage обрезает записи, выбранные репозиторием с запасом в одну запись,
+// до страницы в порядке списка и возвращает курсоры следующей и предыдущей страниц
+func keysetPage[T any](s *Service, scope string, page models.PageQuery, rows []T, key func(T) models.PageKey) ([]T, string, string) {
+	more := len(rows) > page.Limit
+	if more {
+		rows = rows[:page.Limit]
+	}
+	if page.Backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	// Дальше по списку есть записи, если они нашлись при выборке вперед или
+	// если страница получена переходом назад; с предыдущими - наоборот
+	hasNext, hasPrev := more, page.After != nil
+	if page.Backward {
+		hasNext, hasPrev = page.After != nil, more
+	}
+
+	var next, prev string
+	if hasNext {
+		next = s.cursors.Encode(pagination.Cursor{Scope: scope, Key: key(rows[len(rows)-1])})
+	}
+	if hasPrev {
+		prev = s.cursors.Encode(pagination.Cursor{Scope: scope, Key: key(rows[0]), Backward: true})
+	}
+
+	return rows, next, prev
+}
+
+// Области курсоров: курсор одного списка недействителен для другого
+func membersScope(gymID string) string         { return "members:" + gymID }
+func postsScope(gymID string) string           { return "posts:" + gymID }
+func historyScope(gymID, userID string) string { return fmt.Sprintf("history:%s:%s", gymID, userID) }
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"myapp/internal/models"
+)
+
+const (
+	// MaxPostLength - максимальная длина текста публикации в символах
+	MaxPostLength = 5000
+
+	defaultPostsLimit = 20
+	maxPostsLimit     = 100
+)
+
+// ListPosts возвращает страницу ленты группы после cursor.
+// Читать ленту могут участники группы и администраторы.
+func (s *Service) ListPosts(ctx context.Context, actor models.Actor, gymID, cursor string, limit int) (models.PostsPage, error) {
+	if gymID == "" {
+		return models.PostsPage{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if err := s.requireMember(ctx, actor, gymID, false); err != nil {
+		return models.PostsPage{}, err
+	}
+
+	query, err := s.pageQuery(postsScope(gymID), cursor, limit, defaultPostsLimit, maxPostsLimit)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+
+	posts, err := s.repo.ListPosts(ctx, gymID, query)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+
+	page := models.PostsPage{}
+	page.Posts, page.NextCursor, page.PrevCursor = keysetPage(s, postsScope(gymID), query, posts, func(p models.Post) models.PageKey {
+		return models.PageKey{Time: p.CreatedAt, ID: p.ID}
+	})
+
+	if query.After == nil {
+		if page.Pinned, err = s.repo.ListPinnedPosts(ctx, gymID); err != nil {
+			return models.PostsPage{}, err
+		}
+	}
+
+	// У внутренних сервисов нет отметок о прочтении
+	if requireUser(actor) == nil {
+		if page.Unread, err = s.repo.CountUnreadPosts(ctx, gymID, actor.UserID); err != nil {
+			return models.PostsPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+// CreatePost публикует запись в ленте группы от имени пользователя.
+// Закреплять публикации могут только администраторы.
+func (s *Service) CreatePost(ctx context.Context, actor models.Actor, gymID string, req models.PostRequest) (models.Post, error) {
+	if gymID == "" {
+		return models.Post{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if err := requireUser(actor); err != nil {
+		return models.Post{}, err
+	}
+	if err := s.requireMember(ctx, actor, gymID, true); err != nil {
+		return models.Post{}, err
+	}
+
+	post := models.Post{GymID: gymID, AuthorID: actor.UserID}
+	if err := applyPostRequest(&post, actor, req); err != nil {
+		return models.Post{}, err
+	}
+	if post.Text == "" {
+		return models.Post{}, fmt.Errorf("%w: требуется текст публикации", models.ErrInvalidArgument)
+	}
+
+	return s.repo.CreatePost(ctx, post)
+}
+
+// UpdatePost изменяет публикацию. Изменять публикацию могут ее автор и администраторы.
+func (s *Service) UpdatePost(ctx context.Context, actor models.Actor, gymID, postID string, req models.PostRequest) (models.Post, error) {
+	post, err := s.authorPost(ctx, actor, gymID, postID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	if err := applyPostRequest(&post, actor, req); err != nil {
+		return models.Post{}, err
+	}
+
+	return s.repo.UpdatePost(ctx, post)
+}
+
+// DeletePost удаляет публикацию. Удалять публикацию могут ее автор и администраторы.
+func (s *Service) DeletePost(ctx context.Context, actor models.Actor, gymID, postID string) error {
+	if _, err := s.authorPost(ctx, actor, gymID, postID); err != nil {
+		return err
+	}
+
+	return s.repo.DeletePost(ctx, gymID, postID)
+}
+
+// CountUnreadPosts возвращает число непрочитанных публикаций группы для вызывающего
+func (s *Service) CountUnreadPosts(ctx context.Context, actor models.Actor, gymID string) (int, error) {
+	if gymID == "" {
+		return 0, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if err := requireUser(actor); err != nil {
+		return 0, err
+	}
+	if err := s.requireMember(ctx, actor, gymID, false); err != nil {
+		return 0, err
+	}
+
+	return s.repo.CountUnreadPosts(ctx, gymID, actor.UserID)
+}
+
+// MarkPostsRead отмечает ленту группы прочитанной на текущий момент
+func (s *Service) MarkPostsRead(ctx context.Context, actor models.Actor, gymID string) error {
+	if gymID == "" {
+		return fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if err := requireUser(actor); err != nil {
+		return err
+	}
+	if err := s.requireMember(ctx, actor, gymID, false); err != nil {
+		return err
+	}
+
+	return s.repo.MarkPostsRead(ctx, gymID, actor.UserID, time.Now().UTC())
+}
+
+// authorPost получает публикацию, если вызывающий - ее автор или администратор.
+// Автор, который покинул группу, заблокирован или приостановлен, свои публикации
+// не изменяет.
+func (s *Service) authorPost(ctx context.Context, actor models.Actor, gymID, postID string) (models.Post, error) {
+	if gymID == "" || postID == "" {
+		return models.Post{}, fmt.Errorf("%w: требуются ID зала и ID публикации", models.ErrInvalidArgument)
+	}
+	if err := s.requireMember(ctx, actor, gymID, true); err != nil {
+		return models.Post{}, err
+	}
+
+	post, err := s.repo.GetPost(ctx, gymID, postID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	if post.AuthorID != actor.UserID && !isPrivileged(actor) {
+		return models.Post{}, fmt.Errorf("%w: изменять публикацию могут только ее автор и администраторы", models.ErrForbidden)
+	}
+
+	return post, nil
+}
+
+// requireMember проверяет, что вызывающий состоит в группе зала.
+// При write приостановленные участники получают отказ.
+func (s *Service) requireMember(ctx context.Context, actor models.Actor, gymID string, write bool) error {
+	if isPrivileged(actor) {
+		return nil
+	}
+
+	status, err := s.repo.GetUserStatus(ctx, actor.UserID, gymID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return err
+	}
+	if err != nil || !status.IsMember() {
+		return fmt.Errorf("%w: пользователь не состоит в группе зала", models.ErrForbidden)
+	}
+	if write && status == models.SuspendedStatus {
+		return fmt.Errorf("%w: участие в группе приостановлено", models.ErrForbidden)
+	}
+
+	return nil
+}
+
+// isPrivileged сообщает, действует ли вызывающий от имени администратора или сервиса
+func isPrivileged(actor models.Actor) bool {
+	return actor.Role == models.RoleAdmin || actor.Role == models.RolePlatformAdmin || actor.Role == models.RoleService
+}
+
+// requireUser проверяет, что вызывающий действует от имени пользователя: автор
+// публикации и отметка о прочтении ссылаются на учетную запись, а у внутренних
+// сервисов ее нет и идентификатор может быть не UUID
+func requireUser(actor models.Actor) error {
+	if actor.Role == models.RoleService || !isUUID(actor.UserID) {
+		return fmt.Errorf("%w: действие доступно только пользователям", models.ErrForbidden)
+	}
+	return nil
+}
+
+// isUUID сообщает, записан ли id в каноническом виде UUID
+func isUUID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	for i, c := range id {
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return false
+			}
+		case !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'):
+			return false
+		}
+	}
+	return true
+}
+
+// applyPostRequest переносит изменения из запроса в публикацию
+func applyPostRequest(post *models.Post, actor models.Actor, req models.PostRequest) error {
+	if req.Text != nil {
+		text := strings.TrimSpace(*req.Text)
+		if text == "" {
+			return fmt.Errorf("%w: текст публикации не может быть пустым", models.ErrInvalidArgument)
+		}
+		if utf8.RuneCountInString(text) > MaxPostLength {
+			return fmt.Errorf("%w: текст публикации длиннее %d символов", models.ErrInvalidArgument, MaxPostLength)
+		}
+		post.Text = text
+	}
+
+	if req.Pinned != nil && *req.Pinned != post.Pinned {
+		if !isPrivileged(actor) {
+			return fmt.Errorf("%w: закреплять публикации могут только администраторы", models.ErrForbidden)
+		}
+		post.Pinned = *req.Pinned
+	}
+
+	return nil
+}
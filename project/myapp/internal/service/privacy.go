@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"myapp/internal/models"
+)
+
+// ExportMyData собирает персональные данные вызывающего
+func (s *Service) ExportMyData(ctx context.Context, actor models.Actor) (models.DataExport, error) {
+	if actor.UserID == "" {
+		return models.DataExport{}, fmt.Errorf("%w: требуется ID пользователя", models.ErrInvalidArgument)
+	}
+
+	return s.repo.ExportUserData(ctx, actor.UserID)
+}
+
+// EraseMyData удаляет персональные данные вызывающего
+func (s *Service) EraseMyData(ctx context.Context, actor models.Actor, req models.EraseRequest) (models.Erasure, error) {
+	return s.eraseUser(ctx, actor, actor.UserID, req)
+}
+
+// EraseMemberData удаляет персональные данные участника зала по запросу администратора.
+// Администратор платформы удаляет данные пользователя полностью, остальные - только
+// данные в залах организации, которой принадлежит зал. Пользователь должен быть
+// участником зала, иначе он считается ненайденным.
+func (s *Service) EraseMemberData(ctx context.Context, actor models.Actor, gymID, userID string, req models.EraseRequest) (models.Erasure, error) {
+	if gymID == "" || userID == "" {
+		return models.Erasure{}, fmt.Errorf("%w: требуются ID зала и ID пользователя", models.ErrInvalidArgument)
+	}
+	if !isPrivileged(actor) {
+		return models.Erasure{}, fmt.Errorf("%w: удалять данные других пользователей могут только администраторы", models.ErrForbidden)
+	}
+
+	status, err := s.repo.GetUserStatus(ctx, userID, gymID)
+	if err != nil {
+		return models.Erasure{}, err
+	}
+	if !status.IsMember() {
+		return models.Erasure{}, fmt.Errorf("%w: участник не найден", models.ErrNotFound)
+	}
+
+	erasure := newErasure(actor, userID, req)
+	if actor.Role != models.RolePlatformAdmin {
+		gym, err := s.repo.GetGym(ctx, gymID)
+		if err != nil {
+			return models.Erasure{}, err
+		}
+		erasure.OrganizationID = &gym.OrganizationID
+	}
+
+	return s.repo.EraseUser(ctx, erasure)
+}
+
+// eraseUser полностью обезличивает пользователя и записывает удаление в журнал
+func (s *Service) eraseUser(ctx context.Context, actor models.Actor, userID string, req models.EraseRequest) (models.Erasure, error) {
+	if userID == "" {
+		return models.Erasure{}, fmt.Errorf("%w: требуется ID пользователя", models.ErrInvalidArgument)
+	}
+
+	return s.repo.EraseUser(ctx, newErasure(actor, userID, req))
+}
+
+// newErasure создает запись журнала удаления данных userID по запросу actor
+func newErasure(actor models.Actor, userID string, req models.EraseRequest) models.Erasure {
+	erasure := models.Erasure{UserID: userID, ErasedBy: actor.UserID, ActorRole: actor.Role}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		erasure.Reason = &reason
+	}
+	return erasure
+}
@@ -3,77 +3,310 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"myapp/internal/audit"
 	"myapp/internal/models"
+	"myapp/internal/pagination"
 )
 
 // Repository определяет интерфейс для операций с базой данных
 type Repository interface {
 	GetGroupMembers(ctx context.Context, gymID string) ([]models.User, error)
 	GetUserGroup(ctx context.Context, userID string) (models.Group, []models.User, error)
-	UpdateUserStatus(ctx context.Context, userID, gymID string, status models.ActivityStatus) error
+	ListGroupMembersPage(ctx context.Context, gymID string, page models.PageQuery) ([]models.MemberListEntry, error)
+	ListStatusHistory(ctx context.Context, gymID, userID string, page models.PageQuery) ([]models.StatusHistoryEntry, error)
+	GetGroupMembersVersion(ctx context.Context, gymID string) (models.ListVersion, error)
+	GetUserGroupVersion(ctx context.Context, userID string) (models.ListVersion, error)
+	UpdateUserStatus(ctx context.Context, userID, gymID string, change models.StatusChange, check func(models.ActivityStatus) error) (models.MemberStatus, error)
 	GetUserStatus(ctx context.Context, userID, gymID string) (models.ActivityStatus, error)
-	AddUserToGym(ctx context.Context, userID, gymID string) error
+	GetMemberStatus(ctx context.Context, userID, gymID string) (models.MemberStatus, error)
+	AddUserToGym(ctx context.Context, actor models.Actor, userID, gymID string, status models.ActivityStatus) error
+	ImportMembers(ctx context.Context, actor models.Actor, gymID string, rows []models.ImportMemberRow, dryRun bool, check func(from, to models.ActivityStatus) error) ([]models.ImportRowReport, error)
+	StreamGroupMembers(ctx context.Context, gymID string, fn func(models.User) error) error
+	ExportUserData(ctx context.Context, userID string) (models.DataExport, error)
+	EraseUser(ctx context.Context, erasure models.Erasure) (models.Erasure, error)
+	CreateOrganization(ctx context.Context, org models.Organization) (models.Organization, error)
+	ListOrganizations(ctx context.Context) ([]models.Organization, error)
+	CreateGym(ctx context.Context, gym models.Gym) (models.Gym, error)
+	GetGym(ctx context.Context, gymID string) (models.Gym, error)
+	ListGyms(ctx context.Context) ([]models.Gym, error)
+	UpdateGym(ctx context.Context, gym models.Gym) (models.Gym, error)
+	DeleteGym(ctx context.Context, gymID string) error
+	GetGymSettings(ctx context.Context, gymID string) (models.GymSettings, error)
+	UpdateGymSettings(ctx context.Context, settings models.GymSettings) (models.GymSettings, error)
+	CreateInvitation(ctx context.Context, invitation models.Invitation) (models.Invitation, error)
+	ListInvitations(ctx context.Context, gymID string) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, gymID, invitationID string) (models.Invitation, error)
+	RedeemInvitation(ctx context.Context, actor models.Actor, code string, check func(models.Invitation, string) error) (models.Invitation, error)
+	ListJoinRequests(ctx context.Context, gymID string) ([]models.JoinRequest, error)
+	CreatePost(ctx context.Context, post models.Post) (models.Post, error)
+	GetPost(ctx context.Context, gymID, postID string) (models.Post, error)
+	UpdatePost(ctx context.Context, post models.Post) (models.Post, error)
+	DeletePost(ctx context.Context, gymID, postID string) error
+	ListPosts(ctx context.Context, gymID string, page models.PageQuery) ([]models.Post, error)
+	ListPinnedPosts(ctx context.Context, gymID string) ([]models.Post, error)
+	CountUnreadPosts(ctx context.Context, gymID, userID string) (int, error)
+	MarkPostsRead(ctx context.Context, gymID, userID string, readAt time.Time) error
+	CreateVisit(ctx context.Context, visit models.Visit, streakMinVisits int) (models.Visit, error)
+	GetLeaderboard(ctx context.Context, gymID string, since, now time.Time, userID string, limit int) ([]models.LeaderboardEntry, error)
+	RefreshMemberStats(ctx context.Context, gymID, userID string, minVisits int) (int, []models.Badge, error)
+	GetMemberStats(ctx context.Context, gymID, userID string) (models.MemberStats, error)
+	ListMemberBadges(ctx context.Context, gymID, userID string) ([]models.Badge, error)
+	ListBadgeRules(ctx context.Context, gymID string) ([]models.BadgeRule, error)
+	CreateBadgeRule(ctx context.Context, rule models.BadgeRule) (models.BadgeRule, error)
+	DeleteBadgeRule(ctx context.Context, gymID, ruleID string) error
+	GetBuddyPreferences(ctx context.Context, gymID, userID string) (models.BuddyPreferences, error)
+	UpdateBuddyPreferences(ctx context.Context, gymID, userID string, prefs models.BuddyPreferences) (models.BuddyPreferences, error)
+	ListBuddySuggestions(ctx context.Context, gymID, userID, timezone string, since time.Time, limit int) ([]models.BuddySuggestion, error)
+	CreateClass(ctx context.Context, class models.Class) (models.Class, error)
+	GetClass(ctx context.Context, gymID, classID string) (models.Class, error)
+	ListClasses(ctx context.Context, gymID string) ([]models.Class, error)
+	UpdateClass(ctx context.Context, class models.Class) (models.Class, error)
+	DeleteClass(ctx context.Context, gymID, classID string) error
+	ListSessionCounts(ctx context.Context, gymID string, from, to time.Time, userID string) ([]models.SessionCounts, error)
+	BookSession(ctx context.Context, classID string, startsAt time.Time, userID string) (models.Booking, error)
+	CancelBooking(ctx context.Context, classID string, startsAt time.Time, userID string) (models.Booking, error)
 }
 
 // Service обрабатывает бизнес-логику для сервиса групп
 type Service struct {
-	repo Repository
+	repo    Repository
+	cursors *pagination.Signer
 }
 
-// NewService создает новый сервис групп
-func NewService(repo Repository) *Service {
+// NewService создает новый сервис групп. Курсоры постраничных списков подписываются
+// ключом из cursorSecret, общим для всех реплик.
+func NewService(repo Repository, cursorSecret string) *Service {
 	return &Service{
-		repo: repo,
+		repo:    repo,
+		cursors: pagination.NewSigner(cursorSecret),
 	}
 }
 
 // GetGroupMembers получает всех участников зала
 func (s *Service) GetGroupMembers(ctx context.Context, gymID string) ([]models.User, error) {
 	if gymID == "" {
-		return nil, errors.New("требуется ID зала")
+		return nil, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
 	}
 
-	return s.repo.GetGroupMembers(ctx, gymID)
+	users, err := s.repo.GetGroupMembers(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.attachBadges(ctx, gymID, users)
+}
+
+// ListGroupMembersPage получает страницу участников зала в порядке вступления
+func (s *Service) ListGroupMembersPage(ctx context.Context, gymID, cursor string, limit int) (models.MembersPage, error) {
+	if gymID == "" {
+		return models.MembersPage{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	query, err := s.pageQuery(membersScope(gymID), cursor, limit, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		return models.MembersPage{}, err
+	}
+
+	members, err := s.repo.ListGroupMembersPage(ctx, gymID, query)
+	if err != nil {
+		return models.MembersPage{}, err
+	}
+
+	page := models.MembersPage{}
+	page.Members, page.NextCursor, page.PrevCursor = keysetPage(s, membersScope(gymID), query, members, func(m models.MemberListEntry) models.PageKey {
+		return models.PageKey{Time: m.JoinedAt, ID: m.MembershipID}
+	})
+
+	users := make([]models.User, len(page.Members))
+	for i := range page.Members {
+		users[i] = page.Members[i].User
+	}
+	if users, err = s.attachBadges(ctx, gymID, users); err != nil {
+		return models.MembersPage{}, err
+	}
+	for i := range page.Members {
+		page.Members[i].User = users[i]
+	}
+
+	return page, nil
 }
 
 // GetUserGroup получает информацию о группе и участниках для пользователя
 func (s *Service) GetUserGroup(ctx context.Context, userID string) (models.Group, []models.User, error) {
 	if userID == "" {
-		return models.Group{}, nil, errors.New("требуется ID пользователя")
+		return models.Group{}, nil, fmt.Errorf("%w: требуется ID пользователя", models.ErrInvalidArgument)
+	}
+
+	group, users, err := s.repo.GetUserGroup(ctx, userID)
+	if err != nil {
+		return models.Group{}, nil, err
+	}
+
+	users, err = s.attachBadges(ctx, group.GymID, users)
+	return group, users, err
+}
+
+// GetGroupMembersVersion возвращает версию списка участников зала для условных запросов
+func (s *Service) GetGroupMembersVersion(ctx context.Context, gymID string) (models.ListVersion, error) {
+	if gymID == "" {
+		return models.ListVersion{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
 	}
 
-	return s.repo.GetUserGroup(ctx, userID)
+	return s.repo.GetGroupMembersVersion(ctx, gymID)
 }
 
-// UpdateUserStatus обновляет статус пользователя в зале
-func (s *Service) UpdateUserStatus(ctx context.Context, userID, gymID string, status models.ActivityStatus) error {
+// GetUserGroupVersion возвращает версию группы пользователя для условных запросов
+func (s *Service) GetUserGroupVersion(ctx context.Context, userID string) (models.ListVersion, error) {
+	if userID == "" {
+		return models.ListVersion{}, fmt.Errorf("%w: требуется ID пользователя", models.ErrInvalidArgument)
+	}
+
+	return s.repo.GetUserGroupVersion(ctx, userID)
+}
+
+// UpdateUserStatus переводит пользователя в новый статус по правилам машины состояний.
+// Для некоторых переходов требуется причина. Если expectedVersions не пуст,
+// статус меняется, только когда запись участника не изменилась с момента чтения.
+// Возвращает статус и версию записи участника после изменения.
+func (s *Service) UpdateUserStatus(ctx context.Context, actor models.Actor, userID, gymID string, status models.ActivityStatus, reason string, expectedVersions []int64) (models.MemberStatus, error) {
 	if userID == "" || gymID == "" {
-		return errors.New("требуются ID пользователя и ID зала")
+		return models.MemberStatus{}, fmt.Errorf("%w: требуются ID пользователя и ID зала", models.ErrInvalidArgument)
+	}
+
+	kind, err := resolveActor(actor, userID)
+	if err != nil {
+		return models.MemberStatus{}, err
 	}
 
-	// Проверка статуса
-	if status != models.ActiveStatus && status != models.InactiveStatus {
-		return errors.New("недопустимое значение статуса")
+	reason = strings.TrimSpace(reason)
+	change := models.StatusChange{
+		Status:           status,
+		Reason:           reason,
+		ChangedBy:        actor.UserID,
+		ChangedByRole:    actor.Role,
+		ExpectedVersions: expectedVersions,
 	}
 
-	return s.repo.UpdateUserStatus(ctx, userID, gymID, status)
+	var before models.ActivityStatus
+	updated, err := s.repo.UpdateUserStatus(ctx, userID, gymID, change, func(from models.ActivityStatus) error {
+		before = from
+		return checkTransition(from, status, kind, reason)
+	})
+	if err != nil {
+		return models.MemberStatus{}, err
+	}
+
+	recordStatusChange(ctx, userID, gymID, statusValue{Status: before}, statusValue{Status: status, ReasonGiven: reason != ""})
+	return updated, nil
 }
 
 // GetUserStatus получает статус пользователя в зале
 func (s *Service) GetUserStatus(ctx context.Context, userID, gymID string) (models.ActivityStatus, error) {
 	if userID == "" || gymID == "" {
-		return "", errors.New("требуются ID пользователя и ID зала")
+		return "", fmt.Errorf("%w: требуются ID пользователя и ID зала", models.ErrInvalidArgument)
 	}
 
 	return s.repo.GetUserStatus(ctx, userID, gymID)
 }
 
-// AddUserToGym добавляет пользователя в группу зала
-func (s *Service) AddUserToGym(ctx context.Context, userID, gymID string) error {
+// GetMemberStatus получает статус пользователя в зале вместе с версией записи
+func (s *Service) GetMemberStatus(ctx context.Context, userID, gymID string) (models.MemberStatus, error) {
+	if userID == "" || gymID == "" {
+		return models.MemberStatus{}, fmt.Errorf("%w: требуются ID пользователя и ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.GetMemberStatus(ctx, userID, gymID)
+}
+
+// AddUserToGym добавляет пользователя в группу зала согласно правилу вступления
+// и возвращает статус, с которым пользователь добавлен. actor - вызывающая сторона:
+// сам пользователь или внутренний сервис.
+func (s *Service) AddUserToGym(ctx context.Context, actor models.Actor, userID, gymID string) (models.ActivityStatus, error) {
 	if userID == "" || gymID == "" {
-		return errors.New("требуются ID пользователя и ID зала")
+		return "", fmt.Errorf("%w: требуются ID пользователя и ID зала", models.ErrInvalidArgument)
 	}
 
-	return s.repo.AddUserToGym(ctx, userID, gymID)
+	settings, err := s.repo.GetGymSettings(ctx, gymID)
+	if err != nil {
+		return "", err
+	}
+
+	status := models.ActiveStatus
+	switch settings.JoinPolicy {
+	case models.InviteOnlyPolicy:
+		return "", fmt.Errorf("%w: в этот зал можно вступить только по приглашению", models.ErrForbidden)
+	case models.ApprovalPolicy:
+		status = models.PendingStatus
+	}
+
+	// Повторное вступление тоже проходит через машину состояний
+	var before interface{}
+	current, err := s.repo.GetUserStatus(ctx, userID, gymID)
+	if err == nil {
+		before = statusValue{Status: current}
+	}
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+	case err != nil:
+		return "", err
+	case current == models.PendingStatus || current.IsMember():
+		return current, nil
+	case current == models.BannedStatus:
+		return "", fmt.Errorf("%w: пользователь заблокирован в этом зале", models.ErrForbidden)
+	default:
+		if err := checkTransition(current, status, actorJoin, ""); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.repo.AddUserToGym(ctx, actor, userID, gymID, status); err != nil {
+		return "", err
+	}
+
+	// Пользователь мог уже состоять в зале - возвращаем фактический статус
+	actual, err := s.repo.GetUserStatus(ctx, userID, gymID)
+	if err != nil {
+		return "", err
+	}
+
+	recordStatusChange(ctx, userID, gymID, before, statusValue{Status: actual})
+	return actual, nil
+}
+
+// RemoveUserFromGym переводит вызывающего пользователя в статус left по его собственному решению
+func (s *Service) RemoveUserFromGym(ctx context.Context, actor models.Actor, gymID string) error {
+	userID := actor.UserID
+	if userID == "" || gymID == "" {
+		return fmt.Errorf("%w: требуются ID пользователя и ID зала", models.ErrInvalidArgument)
+	}
+
+	change := models.StatusChange{Status: models.LeftStatus, ChangedBy: userID, ChangedByRole: actor.Role}
+	var before models.ActivityStatus
+	_, err := s.repo.UpdateUserStatus(ctx, userID, gymID, change, func(from models.ActivityStatus) error {
+		before = from
+		return checkTransition(from, models.LeftStatus, actorSelf, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	recordStatusChange(ctx, userID, gymID, statusValue{Status: before}, statusValue{Status: models.LeftStatus})
+	return nil
+}
+
+// statusValue - статус участника в журнале аудита. Журнал хранится бессрочно,
+// поэтому текст причины в него не попадает: он остается только в истории статусов,
+// которую очищает удаление данных участника.
+type statusValue struct {
+	Status      models.ActivityStatus `json:"status"`
+	ReasonGiven bool                  `json:"reason_given,omitempty"`
+}
+
+// recordStatusChange сообщает журналу аудита об изменении статуса участника
+func recordStatusChange(ctx context.Context, userID, gymID string, before, after interface{}) {
+	audit.RecordChange(ctx, models.AuditChange{GymID: gymID, UserID: userID, Before: before, After: after})
 }
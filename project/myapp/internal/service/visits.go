@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"myapp/internal/models"
+)
+
+const (
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+// CheckIn отмечает посещение зала и выдает заработанные им награды. Участник отмечает себя сам,
+// администраторы и сервисы могут отметить другого участника и задать время посещения.
+func (s *Service) CheckIn(ctx context.Context, actor models.Actor, gymID string, req models.CheckInRequest) (models.Visit, error) {
+	if gymID == "" {
+		return models.Visit{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	visit := models.Visit{UserID: actor.UserID, GymID: gymID, VisitedAt: time.Now().UTC()}
+	if req.UserID != "" && req.UserID != actor.UserID {
+		if !isPrivileged(actor) {
+			return models.Visit{}, fmt.Errorf("%w: можно отмечать только собственные посещения", models.ErrForbidden)
+		}
+		visit.UserID = req.UserID
+	}
+	if req.VisitedAt != nil {
+		if !isPrivileged(actor) {
+			return models.Visit{}, fmt.Errorf("%w: время посещения могут задавать только администраторы", models.ErrForbidden)
+		}
+		if req.VisitedAt.After(visit.VisitedAt) {
+			return models.Visit{}, fmt.Errorf("%w: время посещения не может быть в будущем", models.ErrInvalidArgument)
+		}
+		visit.VisitedAt = *req.VisitedAt
+	}
+	if visit.UserID == "" {
+		return models.Visit{}, fmt.Errorf("%w: требуется ID пользователя", models.ErrInvalidArgument)
+	}
+
+	status, err := s.repo.GetUserStatus(ctx, visit.UserID, gymID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return models.Visit{}, err
+	}
+	if err != nil || !status.IsMember() {
+		return models.Visit{}, fmt.Errorf("%w: пользователь не состоит в группе зала", models.ErrForbidden)
+	}
+	if status == models.SuspendedStatus {
+		return models.Visit{}, fmt.Errorf("%w: участие в группе приостановлено", models.ErrForbidden)
+	}
+
+	settings, err := s.repo.GetGymSettings(ctx, gymID)
+	if err != nil {
+		return models.Visit{}, err
+	}
+
+	return s.repo.CreateVisit(ctx, visit, settings.StreakMinVisits)
+}
+
+// GetLeaderboard возвращает рейтинг участников группы за период и место вызывающего
+func (s *Service) GetLeaderboard(ctx context.Context, actor models.Actor, gymID string, period models.LeaderboardPeriod, limit int) (models.Leaderboard, error) {
+	if gymID == "" {
+		return models.Leaderboard{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if period == "" {
+		period = models.WeekPeriod
+	}
+	if limit <= 0 || limit > maxLeaderboardLimit {
+		limit = defaultLeaderboardLimit
+	}
+	if err := s.requireMember(ctx, actor, gymID, false); err != nil {
+		return models.Leaderboard{}, err
+	}
+
+	// Неделя и месяц начинаются по местному времени зала
+	loc, err := s.gymLocation(ctx, gymID)
+	if err != nil {
+		return models.Leaderboard{}, err
+	}
+
+	now := time.Now().In(loc)
+	board := models.Leaderboard{GymID: gymID, Period: period}
+
+	var since time.Time
+	switch period {
+	case models.WeekPeriod:
+		since = startOfWeek(now)
+	case models.MonthPeriod:
+		since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case models.AllPeriod:
+	default:
+		return models.Leaderboard{}, fmt.Errorf("%w: недопустимый период рейтинга", models.ErrInvalidArgument)
+	}
+	if !since.IsZero() {
+		since = since.UTC()
+		board.Since = &since
+	}
+
+	entries, err := s.repo.GetLeaderboard(ctx, gymID, since, now, actor.UserID, limit)
+	if err != nil {
+		return models.Leaderboard{}, err
+	}
+
+	// Место вызывающего может оказаться за пределами первых limit мест
+	board.Entries = make([]models.LeaderboardEntry, 0, len(entries))
+	for i := range entries {
+		if entries[i].UserID == actor.UserID {
+			me := entries[i]
+			board.Me = &me
+		}
+		if entries[i].Rank <= limit {
+			board.Entries = append(board.Entries, entries[i])
+		}
+	}
+
+	return board, nil
+}
+
+// startOfWeek возвращает начало недели (понедельник, 00:00), в которую попадает t,
+// по местному времени часового пояса t
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	day := t.AddDate(0, 0, -offset)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, t.Location())
+}
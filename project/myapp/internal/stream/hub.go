@@ -0,0 +1,214 @@
+package stream
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"myapp/internal/events"
+)
+
+// Event - событие зала с его позицией в потоке хаба
+type Event struct {
+	events.Event
+	StreamID string // Идентификатор для Last-Event-ID в формате "<эпоха>-<номер>"
+	seq      int64
+}
+
+// Subscription представляет подписку клиента на события зала
+type Subscription struct {
+	gymID  string
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events возвращает канал событий подписки
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done закрывается, когда хаб отключает подписку (например, из-за переполнения буфера)
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// close отключает подписку
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Hub рассылает события участников подключенным клиентам групп залов.
+//
+// ID событий в базе выдаются при вставке, а уведомления приходят в порядке фиксации
+// транзакций, поэтому событие с меньшим ID может прийти позже. Хаб нумерует события
+// сам в порядке получения; номера действуют в пределах эпохи, которая меняется при
+// запуске хаба и после потери событий.
+type Hub struct {
+	mu            sync.RWMutex
+	subscribers   map[string]map[*Subscription]struct{}
+	history       map[string][]Event
+	evicted       map[string]int64 // наибольший номер события, вытесненного из истории зала
+	epoch         string
+	seq           int64 // номер последнего полученного события
+	historySize   int
+	subscriberBuf int
+}
+
+// NewHub создает новый хаб. historySize задает количество последних событий зала,
+// хранимых для возобновления по Last-Event-ID, subscriberBuf - размер буфера клиента.
+func NewHub(historySize, subscriberBuf int) *Hub {
+	return &Hub{
+		subscribers:   make(map[string]map[*Subscription]struct{}),
+		history:       make(map[string][]Event),
+		evicted:       make(map[string]int64),
+		epoch:         newEpoch(),
+		historySize:   historySize,
+		subscriberBuf: subscriberBuf,
+	}
+}
+
+// Subscribe подписывает клиента на события зала. Если задан lastEventID, возвращает
+// пропущенные события; complete равен false, если часть из них уже вытеснена из
+// истории или ID выдан в другой эпохе и клиенту нужно заново загрузить состояние.
+func (h *Hub) Subscribe(gymID, lastEventID string) (sub *Subscription, missed []Event, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub = &Subscription{
+		gymID:  gymID,
+		events: make(chan Event, h.subscriberBuf),
+		done:   make(chan struct{}),
+	}
+
+	if h.subscribers[gymID] == nil {
+		h.subscribers[gymID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[gymID][sub] = struct{}{}
+
+	complete = true
+	if lastEventID != "" {
+		// События другой эпохи и вытесненные из истории восстановить нельзя
+		seq, ok := h.parseStreamID(lastEventID)
+		if !ok || seq < h.evicted[gymID] {
+			return sub, nil, false
+		}
+		for _, event := range h.history[gymID] {
+			if event.seq > seq {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	return sub, missed, complete
+}
+
+// Unsubscribe отписывает клиента
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(sub)
+}
+
+// remove удаляет подписку; вызывается под блокировкой
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.subscribers[sub.gymID]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.gymID)
+	}
+	sub.close()
+}
+
+// Publish сохраняет событие в истории зала и рассылает его подписчикам.
+// Клиент, не успевающий читать события, отключается, чтобы не задерживать остальных;
+// он может переподключиться с Last-Event-ID.
+func (h *Hub) Publish(ctx context.Context, event events.Event) error {
+	payload, err := event.DecodeMember()
+	if err != nil {
+		return err
+	}
+	gymID := payload.GymID
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	streamEvent := Event{Event: event, StreamID: h.epoch + "-" + strconv.FormatInt(h.seq, 10), seq: h.seq}
+
+	history := append(h.history[gymID], streamEvent)
+	if len(history) > h.historySize {
+		h.evicted[gymID] = history[len(history)-h.historySize-1].seq
+		history = history[len(history)-h.historySize:]
+	}
+	h.history[gymID] = history
+
+	for sub := range h.subscribers[gymID] {
+		select {
+		case sub.events <- streamEvent:
+		default:
+			h.remove(sub)
+		}
+	}
+
+	return nil
+}
+
+// Reset отключает всех подписчиков, очищает историю и начинает новую эпоху.
+// Вызывается, когда часть событий могла быть потеряна: переподключившиеся клиенты получат reset.
+func (h *Hub) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.epoch = newEpoch()
+	h.seq = 0
+	h.history = make(map[string][]Event)
+	h.evicted = make(map[string]int64)
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// Close отключает всех подписчиков
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+
+	return nil
+}
+
+// parseStreamID возвращает номер события из ID текущей эпохи
+func (h *Hub) parseStreamID(id string) (int64, bool) {
+	epoch, number, ok := strings.Cut(id, "-")
+	if !ok || epoch != h.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || seq < 0 || seq > h.seq {
+		return 0, false
+	}
+	return seq, true
+}
+
+// newEpoch генерирует случайный идентификатор эпохи, чтобы ID событий разных
+// процессов и разных эпох не совпадали
+func newEpoch() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
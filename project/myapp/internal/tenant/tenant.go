@@ -0,0 +1,55 @@
+// Package tenant передает организацию и пользователя вызывающей стороны через контекст.
+// Репозиторий ограничивает запросы залами этой организации. Доступ ко всем
+// организациям задается явно (Unscoped) и выдается только администраторам
+// платформы и внутренним сервисам; контекст без организации и без такой отметки
+// не видит ничего.
+package tenant
+
+import "context"
+
+// organizationKey - ключ контекста для ID организации
+type organizationKey struct{}
+
+// allOrganizationsKey - ключ контекста для отметки о доступе ко всем организациям
+type allOrganizationsKey struct{}
+
+// userKey - ключ контекста для ID пользователя
+type userKey struct{}
+
+// WithOrganization ограничивает контекст организацией orgID
+func WithOrganization(ctx context.Context, orgID string) context.Context {
+	ctx = context.WithValue(ctx, allOrganizationsKey{}, false)
+	return context.WithValue(ctx, organizationKey{}, orgID)
+}
+
+// Unscoped явно разрешает контексту доступ ко всем организациям: для администраторов
+// платформы и внутренних сервисов, а также для служебных запросов, например чтобы
+// узнать, какой организации принадлежит зал
+func Unscoped(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, organizationKey{}, "")
+	return context.WithValue(ctx, allOrganizationsKey{}, true)
+}
+
+// OrganizationID возвращает организацию из контекста или пустую строку,
+// если контекст не ограничен организацией
+func OrganizationID(ctx context.Context) string {
+	orgID, _ := ctx.Value(organizationKey{}).(string)
+	return orgID
+}
+
+// AllOrganizations сообщает, разрешен ли контексту доступ ко всем организациям
+func AllOrganizations(ctx context.Context) bool {
+	all, _ := ctx.Value(allOrganizationsKey{}).(bool)
+	return all && OrganizationID(ctx) == ""
+}
+
+// WithUser сохраняет в контексте пользователя, от имени которого выполняется запрос
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserID возвращает пользователя из контекста или пустую строку
+func UserID(ctx context.Context) string {
+	userID, _ := ctx.Value(userKey{}).(string)
+	return userID
+}
@@ -0,0 +1,34 @@
+package webhooks
+
+import (
+	"context"
+
+	"myapp/internal/events"
+)
+
+// Dispatcher реализует events.Publisher и ставит события в очередь доставки вебхуков
+type Dispatcher struct {
+	repo Repository
+}
+
+// NewDispatcher создает новый диспетчер вебхуков
+func NewDispatcher(repo Repository) *Dispatcher {
+	return &Dispatcher{
+		repo: repo,
+	}
+}
+
+// Publish создает доставки события для подписанных вебхуков зала
+func (d *Dispatcher) Publish(ctx context.Context, event events.Event) error {
+	payload, err := event.DecodeMember()
+	if err != nil {
+		return err
+	}
+
+	return d.repo.EnqueueDeliveries(ctx, payload.GymID, event)
+}
+
+// Close ничего не делает: диспетчер не держит ресурсов
+func (d *Dispatcher) Close() error {
+	return nil
+}
@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errForbiddenAddress возвращается при попытке доставки на адрес внутренней сети
+var errForbiddenAddress = errors.New("адрес вебхука не является публичным")
+
+// nonPublicPrefixes - специальные диапазоны, не покрытые методами netip.Addr
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "Этот" сетевой сегмент
+	netip.MustParsePrefix("100.64.0.0/10"),   // Shared address space (CGNAT)
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1
+	netip.MustParsePrefix("198.18.0.0/15"),   // Тестирование производительности
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3
+	netip.MustParsePrefix("240.0.0.0/4"),     // Зарезервировано и broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64 на адреса IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Локальный NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // Документация
+}
+
+// isPublicAddr сообщает, что адрес доступен из интернета и не ведет во внутреннюю сеть
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkHost отклоняет localhost и IP-адреса внутренней сети, указанные в URL явно.
+// Имена хостов проверяются после разрешения DNS в dialControl.
+func checkHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errForbiddenAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublicAddr(addr) {
+		return errForbiddenAddress
+	}
+	return nil
+}
+
+// dialControl проверяет адрес перед подключением, то есть уже после разрешения DNS,
+// поэтому имя, указывающее на внутренний адрес, не обходит проверку
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", errForbiddenAddress, addr)
+	}
+	return nil
+}
+
+// newClient создает HTTP-клиент доставок, который подключается только к публичным
+// адресам, не использует прокси из окружения и не следует перенаправлениям
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
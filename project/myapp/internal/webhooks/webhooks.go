@@ -0,0 +1,200 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"myapp/internal/events"
+	"myapp/internal/models"
+)
+
+// Job представляет доставку, взятую в работу, вместе с адресом и секретом вебхука
+type Job struct {
+	models.WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// Repository определяет интерфейс хранилища вебхуков и журнала доставок
+type Repository interface {
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	ListWebhooks(ctx context.Context, gymID string) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, gymID, webhookID string) (models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	DeleteWebhook(ctx context.Context, gymID, webhookID string) error
+	ListDeliveries(ctx context.Context, gymID, webhookID string, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, gymID, webhookID, deliveryID string) (models.WebhookDelivery, error)
+
+	// EnqueueDeliveries создает доставки события для подходящих включенных вебхуков зала.
+	// Повторный вызов для того же события не создает дубликатов.
+	EnqueueDeliveries(ctx context.Context, gymID string, event events.Event) error
+	// ClaimDueDeliveries берет в работу доставки, время которых наступило,
+	// откладывая их повтор на lease на случай сбоя обработчика
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Job, error)
+	// CompleteDelivery отмечает доставку успешной и сбрасывает счетчик ошибок вебхука
+	CompleteDelivery(ctx context.Context, job Job, statusCode int) error
+	// FailDelivery сохраняет неудачную попытку. Если nextAttempt равен nil, доставка
+	// считается проваленной. Вебхук отключается после disableAfter ошибок подряд.
+	FailDelivery(ctx context.Context, job Job, statusCode int, message string, nextAttempt *time.Time, disableAfter int) error
+}
+
+// Service управляет подписками залов на вебхуки
+type Service struct {
+	repo Repository
+}
+
+// NewService создает новый сервис вебхуков
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// CreateWebhook создает подписку зала. Если секрет не передан, он генерируется.
+func (s *Service) CreateWebhook(ctx context.Context, gymID string, req models.WebhookRequest) (models.Webhook, error) {
+	if gymID == "" {
+		return models.Webhook{}, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+	if err := validateRequest(req); err != nil {
+		return models.Webhook{}, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+
+	webhook := models.Webhook{
+		GymID:      gymID,
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: normalizeEventTypes(req.EventTypes),
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+
+	return s.repo.CreateWebhook(ctx, webhook)
+}
+
+// ListWebhooks возвращает подписки зала
+func (s *Service) ListWebhooks(ctx context.Context, gymID string) ([]models.Webhook, error) {
+	if gymID == "" {
+		return nil, fmt.Errorf("%w: требуется ID зала", models.ErrInvalidArgument)
+	}
+
+	return s.repo.ListWebhooks(ctx, gymID)
+}
+
+// GetWebhook возвращает подписку зала
+func (s *Service) GetWebhook(ctx context.Context, gymID, webhookID string) (models.Webhook, error) {
+	if gymID == "" || webhookID == "" {
+		return models.Webhook{}, fmt.Errorf("%w: требуются ID зала и ID вебхука", models.ErrInvalidArgument)
+	}
+
+	return s.repo.GetWebhook(ctx, gymID, webhookID)
+}
+
+// UpdateWebhook изменяет подписку зала. Повторное включение сбрасывает счетчик ошибок.
+func (s *Service) UpdateWebhook(ctx context.Context, gymID, webhookID string, req models.WebhookRequest) (models.Webhook, error) {
+	webhook, err := s.GetWebhook(ctx, gymID, webhookID)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if err := validateRequest(req); err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook.URL = req.URL
+	webhook.EventTypes = normalizeEventTypes(req.EventTypes)
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if req.Enabled != nil {
+		if *req.Enabled && !webhook.Enabled {
+			webhook.ConsecutiveFailures = 0
+			webhook.DisabledReason = nil
+		}
+		webhook.Enabled = *req.Enabled
+	}
+
+	return s.repo.UpdateWebhook(ctx, webhook)
+}
+
+// DeleteWebhook удаляет подписку зала вместе с журналом доставок
+func (s *Service) DeleteWebhook(ctx context.Context, gymID, webhookID string) error {
+	if gymID == "" || webhookID == "" {
+		return fmt.Errorf("%w: требуются ID зала и ID вебхука", models.ErrInvalidArgument)
+	}
+
+	return s.repo.DeleteWebhook(ctx, gymID, webhookID)
+}
+
+// ListDeliveries возвращает последние доставки вебхука
+func (s *Service) ListDeliveries(ctx context.Context, gymID, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	if gymID == "" || webhookID == "" {
+		return nil, fmt.Errorf("%w: требуются ID зала и ID вебхука", models.ErrInvalidArgument)
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	return s.repo.ListDeliveries(ctx, gymID, webhookID, limit)
+}
+
+// Redeliver ставит доставку в очередь на немедленную повторную отправку
+func (s *Service) Redeliver(ctx context.Context, gymID, webhookID, deliveryID string) (models.WebhookDelivery, error) {
+	if gymID == "" || webhookID == "" || deliveryID == "" {
+		return models.WebhookDelivery{}, fmt.Errorf("%w: требуются ID зала, ID вебхука и ID доставки", models.ErrInvalidArgument)
+	}
+
+	return s.repo.Redeliver(ctx, gymID, webhookID, deliveryID)
+}
+
+// validateRequest проверяет адрес и типы событий подписки
+func validateRequest(req models.WebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: недопустимый URL вебхука", models.ErrInvalidArgument)
+	}
+	if err := checkHost(u.Hostname()); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+
+	for _, t := range req.EventTypes {
+		switch events.Type(t) {
+		case events.MemberJoined, events.MemberStatusChanged, events.MemberLeft:
+		default:
+			return fmt.Errorf("%w: неизвестный тип события %q", models.ErrInvalidArgument, t)
+		}
+	}
+
+	return nil
+}
+
+// normalizeEventTypes убирает повторы. Пустой список означает подписку на все события.
+func normalizeEventTypes(types []string) []string {
+	seen := make(map[string]bool, len(types))
+	result := make([]string, 0, len(types))
+	for _, t := range types {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// generateSecret генерирует случайный секрет для подписи доставок
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
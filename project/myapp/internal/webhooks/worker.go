@@ -0,0 +1,147 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Заголовки запроса доставки вебхука
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// WorkerConfig содержит настройки доставки вебхуков
+type WorkerConfig struct {
+	PollInterval time.Duration // Интервал опроса очереди доставок
+	BatchSize    int           // Количество доставок за один опрос
+	Timeout      time.Duration // Таймаут HTTP-запроса
+	MaxAttempts  int           // Количество попыток до признания доставки проваленной
+	BaseBackoff  time.Duration // Задержка перед первым повтором
+	MaxBackoff   time.Duration // Максимальная задержка между повторами
+	DisableAfter int           // Количество ошибок подряд до отключения вебхука
+}
+
+// Worker отправляет доставки вебхуков с подписью HMAC-SHA256 и повторами
+type Worker struct {
+	repo   Repository
+	client *http.Client
+	cfg    WorkerConfig
+}
+
+// NewWorker создает новый обработчик доставок
+func NewWorker(repo Repository, cfg WorkerConfig) *Worker {
+	return &Worker{
+		repo:   repo,
+		client: newClient(cfg.Timeout),
+		cfg:    cfg,
+	}
+}
+
+// Run обрабатывает очередь доставок до отмены контекста
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Аренда с запасом покрывает отправку всей пачки
+			lease := w.cfg.Timeout*time.Duration(w.cfg.BatchSize) + time.Minute
+			jobs, err := w.repo.ClaimDueDeliveries(ctx, w.cfg.BatchSize, lease)
+			if err != nil {
+				log.Printf("Ошибка получения доставок вебхуков: %v", err)
+				continue
+			}
+
+			for _, job := range jobs {
+				w.deliver(ctx, job)
+			}
+		}
+	}
+}
+
+// deliver отправляет одну доставку и сохраняет результат
+func (w *Worker) deliver(ctx context.Context, job Job) {
+	statusCode, err := w.send(ctx, job)
+	if err == nil {
+		if err := w.repo.CompleteDelivery(ctx, job, statusCode); err != nil {
+			log.Printf("Ошибка сохранения доставки вебхука %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	var nextAttempt *time.Time
+	if attempts := job.Attempts + 1; attempts < w.cfg.MaxAttempts {
+		next := time.Now().Add(Backoff(attempts, w.cfg.BaseBackoff, w.cfg.MaxBackoff))
+		nextAttempt = &next
+	}
+
+	if err := w.repo.FailDelivery(ctx, job, statusCode, err.Error(), nextAttempt, w.cfg.DisableAfter); err != nil {
+		log.Printf("Ошибка сохранения доставки вебхука %s: %v", job.ID, err)
+	}
+}
+
+// send выполняет HTTP-запрос доставки. Успешными считаются ответы 2xx,
+// перенаправления не выполняются и считаются ошибкой.
+func (w *Worker) send(ctx context.Context, job Job) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "group-service-webhooks")
+	req.Header.Set(EventHeader, job.EventType)
+	req.Header.Set(DeliveryHeader, job.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(job.Secret, timestamp, job.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получен ответ %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign вычисляет подпись тела доставки: HMAC-SHA256 от "<timestamp>.<body>"
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff возвращает экспоненциальную задержку перед повтором с номером attempt
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
@@ -0,0 +1,31 @@
+-- Create gym_settings table
+CREATE TABLE IF NOT EXISTS gym_settings (
+    gym_id UUID PRIMARY KEY,
+    require_invitation BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create invitations table
+CREATE TABLE IF NOT EXISTS invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gym_id UUID NOT NULL,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    email VARCHAR(255),
+    max_uses INT,
+    uses INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create invitation_redemptions table
+CREATE TABLE IF NOT EXISTS invitation_redemptions (
+    invitation_id UUID NOT NULL REFERENCES invitations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redeemed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (invitation_id, user_id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_invitations_gym_id ON invitations(gym_id, created_at DESC);
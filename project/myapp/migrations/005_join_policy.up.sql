@@ -0,0 +1,12 @@
+-- Replace require_invitation with join_policy
+ALTER TABLE gym_settings ADD COLUMN IF NOT EXISTS join_policy VARCHAR(20) NOT NULL DEFAULT 'open';
+UPDATE gym_settings SET join_policy = 'invite_only' WHERE require_invitation;
+ALTER TABLE gym_settings DROP COLUMN IF EXISTS require_invitation;
+
+-- Track join request review
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS reviewed_by UUID;
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_group_members_pending ON group_members(gym_id, joined_at) WHERE status = 'pending';
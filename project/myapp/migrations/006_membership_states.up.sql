@@ -0,0 +1,48 @@
+-- Generalize join request review columns to any status change
+ALTER TABLE group_members RENAME COLUMN reviewed_by TO status_changed_by;
+ALTER TABLE group_members RENAME COLUMN reviewed_at TO status_changed_at;
+
+-- Restrict membership status to the states of the membership state machine
+ALTER TABLE group_members ADD CONSTRAINT group_members_status_check
+    CHECK (status IN ('pending', 'active', 'inactive', 'suspended', 'left', 'banned', 'rejected'));
+
+-- Reject status changes that the state machine does not allow
+CREATE OR REPLACE FUNCTION group_members_check_transition() RETURNS trigger AS $$
+BEGIN
+    IF (OLD.status, NEW.status) NOT IN (
+        ('pending', 'active'), ('pending', 'rejected'), ('pending', 'left'),
+        ('active', 'inactive'), ('inactive', 'active'),
+        ('active', 'suspended'), ('inactive', 'suspended'), ('suspended', 'active'),
+        ('active', 'left'), ('inactive', 'left'), ('suspended', 'left'),
+        ('pending', 'banned'), ('active', 'banned'), ('inactive', 'banned'), ('suspended', 'banned'),
+        ('banned', 'left'), ('rejected', 'left'),
+        ('left', 'active'), ('left', 'pending')
+    ) THEN
+        RAISE EXCEPTION 'membership transition from % to % is not allowed', OLD.status, NEW.status
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS group_members_check_transition ON group_members;
+CREATE TRIGGER group_members_check_transition
+    BEFORE UPDATE OF status ON group_members
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION group_members_check_transition();
+
+-- Create member_status_history table
+CREATE TABLE IF NOT EXISTS member_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gym_id UUID NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    changed_by UUID,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_member_status_history_member ON member_status_history(gym_id, user_id, changed_at);
@@ -0,0 +1,22 @@
+-- Create posts table
+CREATE TABLE IF NOT EXISTS posts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gym_id UUID NOT NULL,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create post_reads table: the moment up to which a member has read the group feed
+CREATE TABLE IF NOT EXISTS post_reads (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gym_id UUID NOT NULL,
+    last_read_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, gym_id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_posts_feed ON posts(gym_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_pinned ON posts(gym_id, created_at DESC) WHERE pinned;
@@ -0,0 +1,12 @@
+-- Create visits table
+CREATE TABLE IF NOT EXISTS visits (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gym_id UUID NOT NULL,
+    visited_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_visits_gym_visited_at ON visits(gym_id, visited_at);
+CREATE INDEX IF NOT EXISTS idx_visits_member ON visits(gym_id, user_id, visited_at);
@@ -0,0 +1,40 @@
+-- Per-gym timezone used to expand class recurrence
+ALTER TABLE gym_settings ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Create classes table: starts_at is local wall-clock time in the gym timezone
+CREATE TABLE IF NOT EXISTS classes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gym_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    starts_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    duration_minutes INT NOT NULL CHECK (duration_minutes > 0),
+    rrule TEXT NOT NULL DEFAULT '',
+    capacity INT NOT NULL CHECK (capacity > 0),
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create class_sessions table: one row per booked occurrence, locked while booking
+CREATE TABLE IF NOT EXISTS class_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    class_id UUID NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (class_id, starts_at)
+);
+
+-- Create class_bookings table
+CREATE TABLE IF NOT EXISTS class_bookings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES class_sessions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('confirmed', 'waitlisted', 'cancelled')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_classes_gym_id ON classes(gym_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_class_bookings_active ON class_bookings(session_id, user_id) WHERE status <> 'cancelled';
+CREATE INDEX IF NOT EXISTS idx_class_bookings_waitlist ON class_bookings(session_id, created_at, id) WHERE status = 'waitlisted';
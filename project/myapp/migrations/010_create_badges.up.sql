@@ -0,0 +1,48 @@
+-- Minimum visits per week for the week to count towards a streak
+ALTER TABLE gym_settings ADD COLUMN IF NOT EXISTS streak_min_visits INT NOT NULL DEFAULT 1;
+
+-- Create member_stats table: per-member rollup of visits and weekly streaks
+CREATE TABLE IF NOT EXISTS member_stats (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gym_id UUID NOT NULL,
+    total_visits INT NOT NULL DEFAULT 0,
+    current_streak INT NOT NULL DEFAULT 0,
+    longest_streak INT NOT NULL DEFAULT 0,
+    streak_week TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, gym_id)
+);
+
+-- Create badge_rules table: rules without gym_id apply to every gym
+CREATE TABLE IF NOT EXISTS badge_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gym_id UUID,
+    code VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('visits', 'streak')),
+    threshold INT NOT NULL CHECK (threshold > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create member_badges table
+CREATE TABLE IF NOT EXISTS member_badges (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gym_id UUID NOT NULL,
+    rule_id UUID NOT NULL REFERENCES badge_rules(id) ON DELETE CASCADE,
+    awarded_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, gym_id, rule_id)
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_badge_rules_code ON badge_rules(COALESCE(gym_id, '00000000-0000-0000-0000-000000000000'::uuid), code);
+CREATE INDEX IF NOT EXISTS idx_member_badges_gym_id ON member_badges(gym_id);
+
+-- Default badges for every gym
+INSERT INTO badge_rules (code, title, kind, threshold) VALUES
+    ('first_visit', 'Первое посещение', 'visits', 1),
+    ('visits_10', '10 посещений', 'visits', 10),
+    ('visits_50', '50 посещений', 'visits', 50),
+    ('visits_100', '100 посещений', 'visits', 100),
+    ('streak_4_weeks', '4 недели подряд', 'streak', 4),
+    ('streak_12_weeks', '12 недель подряд', 'streak', 12)
+ON CONFLICT DO NOTHING;
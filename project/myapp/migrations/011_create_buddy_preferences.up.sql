@@ -0,0 +1,12 @@
+-- Create buddy_preferences table: members opt in to workout buddy suggestions
+CREATE TABLE IF NOT EXISTS buddy_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gym_id UUID NOT NULL,
+    opted_in BOOLEAN NOT NULL DEFAULT FALSE,
+    hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, gym_id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_buddy_preferences_visible ON buddy_preferences(gym_id) WHERE opted_in AND NOT hidden;
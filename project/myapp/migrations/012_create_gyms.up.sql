@@ -0,0 +1,48 @@
+-- Create gyms table: gym_id in groups and group_members now references a real gym
+CREATE TABLE IF NOT EXISTS gyms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    -- Weekly opening periods: [{"weekday": "mon", "opens": "07:00", "closes": "23:00"}, ...]
+    opening_hours JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK ((latitude IS NULL) = (longitude IS NULL))
+);
+
+-- Placeholder gyms for IDs that were used before the table existed;
+-- the timezone moves over from gym_settings
+INSERT INTO gyms (id, name, timezone)
+SELECT ids.gym_id, COALESCE(g.name, 'Gym ' || ids.gym_id::text), COALESCE(s.timezone, 'UTC')
+FROM (
+    SELECT gym_id FROM groups
+    UNION
+    SELECT gym_id FROM group_members
+    UNION
+    SELECT gym_id FROM gym_settings
+) ids
+LEFT JOIN LATERAL (SELECT name FROM groups WHERE gym_id = ids.gym_id ORDER BY created_at LIMIT 1) g ON TRUE
+LEFT JOIN gym_settings s ON s.gym_id = ids.gym_id
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE gym_settings DROP COLUMN IF EXISTS timezone;
+
+-- Every gym keeps a single group
+INSERT INTO groups (gym_id, name)
+SELECT id, name FROM gyms
+WHERE NOT EXISTS (SELECT 1 FROM groups WHERE groups.gym_id = gyms.id);
+
+-- Deleting a gym removes its group; a gym with members cannot be deleted
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS groups_gym_id_fkey;
+ALTER TABLE groups ADD CONSTRAINT groups_gym_id_fkey
+    FOREIGN KEY (gym_id) REFERENCES gyms(id) ON DELETE CASCADE;
+
+ALTER TABLE group_members DROP CONSTRAINT IF EXISTS group_members_gym_id_fkey;
+ALTER TABLE group_members ADD CONSTRAINT group_members_gym_id_fkey
+    FOREIGN KEY (gym_id) REFERENCES gyms(id) ON DELETE RESTRICT;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_groups_gym_id ON groups(gym_id);
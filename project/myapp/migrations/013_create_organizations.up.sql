@@ -0,0 +1,25 @@
+-- Create organizations table: a fitness chain owning gyms; data never crosses organizations
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE gyms ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+
+-- Existing gyms move to a default organization
+DO $$
+DECLARE
+    default_org UUID;
+BEGIN
+    IF EXISTS (SELECT 1 FROM gyms WHERE organization_id IS NULL) THEN
+        INSERT INTO organizations (name) VALUES ('Default organization') RETURNING id INTO default_org;
+        UPDATE gyms SET organization_id = default_org WHERE organization_id IS NULL;
+    END IF;
+END $$;
+
+ALTER TABLE gyms ALTER COLUMN organization_id SET NOT NULL;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_gyms_organization_id ON gyms(organization_id);
@@ -0,0 +1,64 @@
+-- Row-level security on groups, group_members and users.
+-- Policies apply only to non-owner roles: the service enables them by connecting as
+-- group_service_app (or switching to it with SET LOCAL ROLE) and setting
+-- app.tenant_id and app.user_id in every transaction. app.tenant_id = '*' means
+-- a caller that works across organizations; without the setting no rows are visible.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'group_service_app') THEN
+        CREATE ROLE group_service_app NOLOGIN;
+    END IF;
+END $$;
+
+GRANT group_service_app TO CURRENT_USER;
+GRANT USAGE ON SCHEMA public TO group_service_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO group_service_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO group_service_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO group_service_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO group_service_app;
+
+-- A gym is visible to its organization and to cross-organization callers
+CREATE OR REPLACE FUNCTION app_gym_visible(gym UUID) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT current_setting('app.tenant_id', true) = '*'
+        OR EXISTS (
+            SELECT 1 FROM gyms
+            WHERE gyms.id = gym AND gyms.organization_id::text = current_setting('app.tenant_id', true)
+        )
+$$;
+
+-- A user is visible to themselves and to organizations where they have a membership
+CREATE OR REPLACE FUNCTION app_user_visible(member UUID) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT current_setting('app.tenant_id', true) = '*'
+        OR member::text = current_setting('app.user_id', true)
+        OR EXISTS (
+            SELECT 1 FROM group_members gm
+            WHERE gm.user_id = member AND app_gym_visible(gm.gym_id)
+        )
+$$;
+
+ALTER TABLE groups ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS groups_tenant ON groups;
+CREATE POLICY groups_tenant ON groups
+    USING (app_gym_visible(gym_id))
+    WITH CHECK (app_gym_visible(gym_id));
+
+ALTER TABLE group_members ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS group_members_tenant ON group_members;
+CREATE POLICY group_members_tenant ON group_members
+    USING (app_gym_visible(gym_id))
+    WITH CHECK (app_gym_visible(gym_id));
+
+-- Users are global identities: anyone may create one, but only visible users
+-- can be read, changed or deleted
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS users_select ON users;
+DROP POLICY IF EXISTS users_insert ON users;
+DROP POLICY IF EXISTS users_update ON users;
+DROP POLICY IF EXISTS users_delete ON users;
+CREATE POLICY users_select ON users FOR SELECT USING (app_user_visible(id));
+CREATE POLICY users_insert ON users FOR INSERT WITH CHECK (true);
+CREATE POLICY users_update ON users FOR UPDATE USING (app_user_visible(id));
+CREATE POLICY users_delete ON users FOR DELETE USING (app_user_visible(id));
@@ -0,0 +1,16 @@
+-- Erased users keep their row (and visits, stats and badges) under an anonymized identity
+ALTER TABLE users ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP WITH TIME ZONE;
+
+-- Create user_erasures table: append-only audit log of personal data erasures
+CREATE TABLE IF NOT EXISTS user_erasures (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    erased_by VARCHAR(255) NOT NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    reason TEXT,
+    gym_ids UUID[] NOT NULL DEFAULT '{}',
+    erased_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_user_erasures_user_id ON user_erasures(user_id);
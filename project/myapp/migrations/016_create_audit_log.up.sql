@@ -0,0 +1,54 @@
+-- Create audit_log table: one record per mutating API call
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    request_id VARCHAR(64) NOT NULL,
+    organization_id UUID,
+    actor_id VARCHAR(255) NOT NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    target_ids JSONB NOT NULL DEFAULT '{}',
+    gym_id VARCHAR(255),
+    target_user_id VARCHAR(255),
+    before_value JSONB,
+    after_value JSONB,
+    status_code INT NOT NULL,
+    outcome VARCHAR(10) NOT NULL CHECK (outcome IN ('success', 'failure')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log(actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_gym_id ON audit_log(gym_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+
+-- Audit records can only be appended: updates, deletes and truncation are rejected
+-- for every role, including the table owner
+CREATE OR REPLACE FUNCTION reject_audit_change() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'table % is append-only', TG_TABLE_NAME;
+END $$;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
+
+DROP TRIGGER IF EXISTS audit_log_no_truncate ON audit_log;
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_change();
+
+-- The erasure log is an audit log too
+DROP TRIGGER IF EXISTS user_erasures_append_only ON user_erasures;
+CREATE TRIGGER user_erasures_append_only
+    BEFORE UPDATE OR DELETE ON user_erasures
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
+
+DROP TRIGGER IF EXISTS user_erasures_no_truncate ON user_erasures;
+CREATE TRIGGER user_erasures_no_truncate
+    BEFORE TRUNCATE ON user_erasures
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_change();
+
+REVOKE UPDATE, DELETE, TRUNCATE ON audit_log, user_erasures FROM group_service_app;
@@ -0,0 +1,17 @@
+-- Create idempotency_keys table: responses to POST and PUT requests sent with an
+-- Idempotency-Key header, replayed for retries until expires_at
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    actor_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INT,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (actor_id, key)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,17 @@
+-- Version of each membership row for optimistic concurrency (ETag / If-Match)
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- Bump the version on every change, whichever code path updates the row
+CREATE OR REPLACE FUNCTION group_members_bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS group_members_bump_version ON group_members;
+CREATE TRIGGER group_members_bump_version
+    BEFORE UPDATE ON group_members
+    FOR EACH ROW
+    WHEN (OLD.* IS DISTINCT FROM NEW.*)
+    EXECUTE FUNCTION group_members_bump_version();
@@ -0,0 +1,13 @@
+-- Keyset pagination orders lists by (timestamp, id); the timestamp must never be NULL
+UPDATE group_members SET joined_at = COALESCE(updated_at, NOW()) WHERE joined_at IS NULL;
+ALTER TABLE group_members ALTER COLUMN joined_at SET NOT NULL;
+
+UPDATE member_status_history SET changed_at = NOW() WHERE changed_at IS NULL;
+ALTER TABLE member_status_history ALTER COLUMN changed_at SET NOT NULL;
+
+-- Members list in join order
+CREATE INDEX IF NOT EXISTS idx_group_members_joined ON group_members(gym_id, joined_at, id);
+
+-- Status history of a member, newest first; supersedes idx_member_status_history_member
+CREATE INDEX IF NOT EXISTS idx_member_status_history_page ON member_status_history(gym_id, user_id, changed_at DESC, id DESC);
+DROP INDEX IF EXISTS idx_member_status_history_member;
@@ -0,0 +1,3 @@
+-- Erasure requested by an organization admin removes only that organization's data;
+-- NULL means the user's data was erased everywhere
+ALTER TABLE user_erasures ADD COLUMN IF NOT EXISTS organization_id UUID;
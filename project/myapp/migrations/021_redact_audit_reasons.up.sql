@@ -0,0 +1,20 @@
+-- Status change reasons are free text and may contain personal data. The audit log
+-- keeps records forever and now stores only whether a reason was given, so reasons
+-- already recorded are replaced with that flag. The append-only trigger is disabled
+-- for this one-off rewrite only.
+ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only;
+
+UPDATE audit_log
+SET before_value = CASE
+        WHEN before_value ? 'reason'
+        THEN (before_value - 'reason') || jsonb_build_object('reason_given', true)
+        ELSE before_value
+    END,
+    after_value = CASE
+        WHEN after_value ? 'reason'
+        THEN (after_value - 'reason') || jsonb_build_object('reason_given', true)
+        ELSE after_value
+    END
+WHERE before_value ? 'reason' OR after_value ? 'reason';
+
+ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only;
@@ -0,0 +1,5 @@
+-- Status changes can be made by internal services whose token subject is not a user UUID:
+-- the actor is stored as text together with its role, as in audit_log and user_erasures
+ALTER TABLE group_members ALTER COLUMN status_changed_by TYPE VARCHAR(255) USING status_changed_by::text;
+ALTER TABLE member_status_history ALTER COLUMN changed_by TYPE VARCHAR(255) USING changed_by::text;
+ALTER TABLE member_status_history ADD COLUMN IF NOT EXISTS changed_by_role VARCHAR(20);
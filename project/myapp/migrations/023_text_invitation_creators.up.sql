@@ -0,0 +1,4 @@
+-- Invitations can be created by internal services whose token subject is not a user UUID:
+-- the creator is stored as text together with its role
+ALTER TABLE invitations ALTER COLUMN created_by TYPE VARCHAR(255) USING created_by::text;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS created_by_role VARCHAR(20);
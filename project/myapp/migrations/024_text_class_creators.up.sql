@@ -0,0 +1,2 @@
+-- Classes can be created by internal services whose token subject is not a user UUID
+ALTER TABLE classes ALTER COLUMN created_by TYPE VARCHAR(255) USING created_by::text;
@@ -0,0 +1,3 @@
+-- The relay claims a batch with a lease and publishes it outside the transaction;
+-- an expired lease lets another replica pick the events up again
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+-- Response headers such as ETag and Location, replayed together with the stored body
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS response_headers JSONB;
@@ -0,0 +1,12 @@
+-- Whether a user also belongs to a gym of an organization other than the given gym's.
+-- SECURITY DEFINER lets the check see memberships hidden from the caller by row-level
+-- security; it reveals only a boolean.
+CREATE OR REPLACE FUNCTION app_user_in_other_organization(member UUID, gym UUID) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM group_members gm
+        JOIN gyms g ON g.id = gm.gym_id
+        WHERE gm.user_id = member
+            AND g.organization_id IS DISTINCT FROM (SELECT organization_id FROM gyms WHERE id = gym)
+    )
+$$;